@@ -0,0 +1,195 @@
+package atool
+
+import (
+	"go/ast"
+	"strings"
+	"unicode"
+)
+
+// Annotations are markers of comments: key to values in order of appearance.
+// Supported forms are directives (//astools:gen mock, //astools:gen=mock) with key astools:gen
+// and plus markers (// +rpc:path=/v1/launch) with key rpc:path. Marker without value has empty value
+type Annotations map[string][]string
+
+// Has checks that marker with the key exists
+func (a Annotations) Has(key string) bool {
+	_, ok := a[key]
+	return ok
+}
+
+// Get returns first value of marker or empty string
+func (a Annotations) Get(key string) string {
+	if values := a[key]; len(values) > 0 {
+		return values[0]
+	}
+	return ""
+}
+
+// ParseAnnotations extracts markers from comments. Only markers started with one of prefixes are used
+// (ex: astools:, +rpc:), all markers are used if prefixes are empty. Returns nil if there are no markers
+func ParseAnnotations(prefixes []string, groups ...*ast.CommentGroup) Annotations {
+	var res Annotations
+	for _, group := range groups {
+		if group == nil {
+			continue
+		}
+		for _, comment := range group.List {
+			marker, ok := commentMarker(comment.Text)
+			if !ok || !hasAnyPrefix(marker, prefixes) {
+				continue
+			}
+			key, value := splitMarker(strings.TrimPrefix(marker, "+"))
+			if key == "" {
+				continue
+			}
+			if res == nil {
+				res = make(Annotations)
+			}
+			res[key] = append(res[key], value)
+		}
+	}
+	return res
+}
+
+func (p *Printer) annotations(groups ...*ast.CommentGroup) Annotations {
+	if p == nil {
+		return ParseAnnotations(nil, groups...)
+	}
+	return ParseAnnotations(p.prefixes, groups...)
+}
+
+// commentMarker returns directive (name:text right after //) or plus marker (+text) of line comment
+func commentMarker(text string) (string, bool) {
+	if !strings.HasPrefix(text, "//") {
+		return "", false
+	}
+	text = text[2:]
+	if isDirective(text) {
+		return strings.TrimSpace(text), true
+	}
+	text = strings.TrimSpace(text)
+	if len(text) > 1 && text[0] == '+' {
+		return text, true
+	}
+	return "", false
+}
+
+// isDirective checks format name:text without leading spaces (same as Go directives like //go:generate)
+func isDirective(text string) bool {
+	colon := strings.Index(text, ":")
+	if colon <= 0 || colon+1 >= len(text) || unicode.IsSpace(rune(text[colon+1])) {
+		return false
+	}
+	for _, r := range text[:colon] {
+		if !unicode.IsLetter(r) && !unicode.IsDigit(r) && r != '_' && r != '-' && r != '.' {
+			return false
+		}
+	}
+	return true
+}
+
+// splitMarker splits marker to key and value by first = or space
+func splitMarker(marker string) (string, string) {
+	end := strings.IndexFunc(marker, func(r rune) bool { return r == '=' || unicode.IsSpace(r) })
+	if end < 0 {
+		return marker, ""
+	}
+	return marker[:end], strings.TrimSpace(marker[end+1:])
+}
+
+func hasAnyPrefix(text string, prefixes []string) bool {
+	if len(prefixes) == 0 {
+		return true
+	}
+	for _, prefix := range prefixes {
+		if strings.HasPrefix(text, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// WithAnnotation returns copy of package with top-level declarations marked by the annotation key.
+// Enums are selected by annotations of their types
+func (p *Package) WithAnnotation(key string) *Package {
+	cp := *p
+	cp.Structs = annotatedStructs(key, p.Structs)
+	cp.Interfaces = annotatedInterfaces(key, p.Interfaces)
+	cp.Funcs = annotatedFuncs(key, p.Funcs)
+	cp.Types = annotatedTypes(key, p.Types)
+	cp.Values = annotatedValues(key, p.Values)
+	cp.Enums = annotatedEnums(key, p.Enums)
+	return &cp
+}
+
+// WithAnnotation returns copy of file with top-level declarations marked by the annotation key
+func (f *File) WithAnnotation(key string) *File {
+	cp := *f
+	cp.Structs = annotatedStructs(key, f.Structs)
+	cp.Interfaces = annotatedInterfaces(key, f.Interfaces)
+	cp.Funcs = annotatedFuncs(key, f.Funcs)
+	cp.Types = annotatedTypes(key, f.Types)
+	cp.Values = annotatedValues(key, f.Values)
+	cp.Enums = annotatedEnums(key, f.Enums)
+	return &cp
+}
+
+func annotatedStructs(key string, list []*Struct) []*Struct {
+	var res []*Struct
+	for _, v := range list {
+		if v.Annotations.Has(key) {
+			res = append(res, v)
+		}
+	}
+	return res
+}
+
+func annotatedInterfaces(key string, list []*Interface) []*Interface {
+	var res []*Interface
+	for _, v := range list {
+		if v.Annotations.Has(key) {
+			res = append(res, v)
+		}
+	}
+	return res
+}
+
+func annotatedFuncs(key string, list []*Func) []*Func {
+	var res []*Func
+	for _, v := range list {
+		if v.Annotations.Has(key) {
+			res = append(res, v)
+		}
+	}
+	return res
+}
+
+func annotatedTypes(key string, list []*NamedType) []*NamedType {
+	var res []*NamedType
+	for _, v := range list {
+		if v.Annotations.Has(key) {
+			res = append(res, v)
+		}
+	}
+	return res
+}
+
+func annotatedValues(key string, list []*Value) []*Value {
+	var res []*Value
+	for _, v := range list {
+		if v.Annotations.Has(key) {
+			res = append(res, v)
+		}
+	}
+	return res
+}
+
+func annotatedEnums(key string, list []*Enum) []*Enum {
+	var res []*Enum
+	for _, v := range list {
+		if v.Type != nil && v.Type.Annotations.Has(key) {
+			res = append(res, v)
+		}
+	}
+	return res
+}
@@ -0,0 +1,43 @@
+package atool
+
+import (
+	"github.com/alecthomas/assert"
+	"testing"
+)
+
+func TestAnnotations(t *testing.T) {
+	f, err := Scan("test/annotations/annotations.go")
+	assert.Nil(t, err)
+
+	launcher := f.Interface("Launcher")
+	assert.Equal(t, []string{"mock", "client"}, launcher.Annotations["astools:gen"])
+	assert.Equal(t, "echo launcher", launcher.Annotations.Get("go:generate"))
+	assert.Equal(t, "Launcher starts rockets\n", launcher.Doc)
+
+	launch := launcher.Method("Launch")
+	assert.Equal(t, "/v1/launch", launch.Annotations.Get("rpc:path"))
+	assert.Equal(t, "POST", launch.Annotations.Get("rpc:method"))
+
+	rocket := f.Struct("Rocket")
+	assert.Nil(t, rocket.Annotations)
+	assert.True(t, rocket.Field("Name").Annotations.Has("required"))
+	assert.Equal(t, "", rocket.Field("Name").Annotations.Get("required"))
+	assert.Equal(t, "10", rocket.Field("Power").Annotations.Get("astools:default"))
+
+	assert.Equal(t, "enum", f.Type("Status").Annotations.Get("astools:gen"))
+	assert.Equal(t, "ready", f.Enum("Status").Value("Ready").Annotations.Get("label"))
+
+	filtered := f.WithAnnotation("astools:gen")
+	assert.Len(t, filtered.Interfaces, 1)
+	assert.Len(t, filtered.Structs, 0)
+	assert.Len(t, filtered.Types, 1)
+	assert.Len(t, filtered.Enums, 1)
+	assert.Len(t, f.Structs, 1)
+
+	cfg := &Config{AnnotationPrefixes: []string{"+rpc:"}}
+	f, err = cfg.Scan("test/annotations/annotations.go")
+	assert.Nil(t, err)
+	assert.Nil(t, f.Interface("Launcher").Annotations)
+	assert.Len(t, f.Interface("Launcher").Method("Launch").Annotations, 2)
+	assert.Nil(t, f.Struct("Rocket").Field("Name").Annotations)
+}
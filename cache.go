@@ -0,0 +1,448 @@
+package atool
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/sha256"
+	"encoding/gob"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"github.com/pkg/errors"
+	"go/ast"
+	"go/token"
+	"go/types"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+)
+
+// Cache shares scanned and type-checked imported packages between files, scans and configs with the same resolver,
+// so packages used by many types (ex: standard library) are parsed once. Entries are checked by names, sizes and
+// modification times of files in directory (by hash of content for files of overlay), changed package replaces
+// the cached one. Type-checked packages are checked together with their dependencies.
+// Cached files are shared and must not be modified. Imports of cached package are resolved with overlay of config
+// which scanned it first.
+//
+// If Dir is set, scanned imported packages are persisted between runs: sources of package files with blanked bodies
+// of functions (positions are kept) are parsed instead of original files, which is several times faster than
+// parsing original sources and decoding syntax trees. Names of packages are persisted too: imports which can't
+// declare requested type are skipped without parsing. Type-checked packages live only in memory.
+//
+// Only imported packages are cached: explicitly scanned files (Scan, ScanPackage, ScanPatterns) are always parsed,
+// because they are returned to caller and could be modified. Cache is safe for concurrent use
+type Cache struct {
+	Dir      string // optional directory to persist scanned packages, created on demand
+	lock     sync.Mutex
+	packages map[string]*cachedPackage  // by directory, import path and config
+	typed    map[string]*typedPackage   // by directory, import path and config
+	names    map[string]*packageSummary // by directory and build context
+	tokens   *token.FileSet             // positions of type-checked packages
+}
+
+// NewCache creates in-memory cache. Scanned packages are persisted to the directory if it's not empty
+func NewCache(dir string) *Cache {
+	return &Cache{Dir: dir}
+}
+
+type cachedPackage struct {
+	ready chan struct{} // closed when scan is finished
+	stamp string
+	files []*File
+	err   error
+}
+
+// typedPackage is a type-checked imported package with cached dependencies
+type typedPackage struct {
+	key   string
+	dir   string
+	stamp string
+	pkg   *types.Package
+	deps  []*typedPackage
+}
+
+// packageSummary is a persisted name of scanned package
+type packageSummary struct {
+	Stamp   string
+	Package string
+}
+
+// packageSources are persisted sources of scanned package files without bodies of functions
+type packageSources struct {
+	Stamp string
+	Files []packageSource
+}
+
+type packageSource struct {
+	Name   string // base name of file
+	Source []byte
+}
+
+// scanImport parses all files of imported package. Scanned packages are shared by cache of config (if set)
+func (cfg *Config) scanImport(dir, importPath string) ([]*File, error) {
+	if cfg.Cache == nil {
+		return cfg.scanImportDir(dir, importPath)
+	}
+	return cfg.Cache.scan(cfg, dir, importPath)
+}
+
+func (cfg *Config) scanImportDir(dir, importPath string) ([]*File, error) {
+	files, err := cfg.scanDir(token.NewFileSet(), dir)
+	if err != nil {
+		return nil, err
+	}
+	linkImport(files, importPath)
+	return files, nil
+}
+
+// scanImportSources parses persisted sources of imported package
+func (cfg *Config) scanImportSources(dir string, sources []packageSource, importPath string) ([]*File, error) {
+	tokens := token.NewFileSet()
+	var files []*File
+	for _, src := range sources {
+		file, err := cfg.scanSource(tokens, filepath.Join(dir, src.Name), src.Source)
+		if err != nil {
+			return nil, errors.Wrapf(err, "scan persisted source %v", src.Name)
+		}
+		files = append(files, file)
+	}
+	linkImport(files, importPath)
+	return files, nil
+}
+
+// linkImport marks files as files of the same imported package
+func linkImport(files []*File, importPath string) {
+	for _, file := range files {
+		file.Import = importPath
+		file.near = files
+	}
+	attachPackageMethods(files, nil)
+}
+
+// importName returns name of package in directory if it's known by cache of config, otherwise empty string
+func (cfg *Config) importName(dir string) string {
+	if cfg.Cache == nil {
+		return ""
+	}
+	return cfg.Cache.packageName(cfg, dir)
+}
+
+func (c *Cache) scan(cfg *Config, dir, importPath string) ([]*File, error) {
+	stamp, err := cfg.stamp(dir)
+	if err != nil {
+		return nil, err
+	}
+	key := cfg.cacheKey(dir) + "\n" + importPath
+	c.lock.Lock()
+	if c.packages == nil {
+		c.packages = make(map[string]*cachedPackage)
+	}
+	if entry, ok := c.packages[key]; ok && entry.stamp == stamp {
+		c.lock.Unlock()
+		<-entry.ready
+		return entry.files, entry.err
+	}
+	entry := &cachedPackage{ready: make(chan struct{}), stamp: stamp}
+	c.packages[key] = entry
+	c.lock.Unlock()
+
+	entry.files, entry.err = c.scanPackage(cfg, dir, importPath, stamp)
+	close(entry.ready)
+	if entry.err != nil {
+		// failed scans are not cached: next call tries again
+		c.lock.Lock()
+		if c.packages[key] == entry {
+			delete(c.packages, key)
+		}
+		c.lock.Unlock()
+		return nil, entry.err
+	}
+	if name := packageOf(entry.files); name != "" {
+		c.save(cfg.summaryKey(dir), &packageSummary{Stamp: stamp, Package: name})
+	}
+	return entry.files, nil
+}
+
+// scanPackage scans imported package from persisted sources if they are not outdated, otherwise from directory.
+// Scanned package is persisted
+func (c *Cache) scanPackage(cfg *Config, dir, importPath, stamp string) ([]*File, error) {
+	key := cfg.summaryKey(dir)
+	if stored := c.loadSources(key); stored != nil && stored.Stamp == stamp {
+		if files, err := cfg.scanImportSources(dir, stored.Files, importPath); err == nil {
+			return files, nil
+		}
+	}
+	files, err := cfg.scanImportDir(dir, importPath)
+	if err != nil {
+		return nil, err
+	}
+	c.saveSources(key, stamp, files)
+	return files, nil
+}
+
+// fileSet returns shared file set of type-checked packages
+func (c *Cache) fileSet() *token.FileSet {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	if c.tokens == nil {
+		c.tokens = token.NewFileSet()
+	}
+	return c.tokens
+}
+
+// importTyped returns type-checked package from cache if it and all its dependencies are not changed,
+// otherwise checks package by the importer and saves it
+func (c *Cache) importTyped(imp *typedImporter, path, dir string) (*types.Package, error) {
+	key := imp.cfg.cacheKey(dir) + "\n" + path
+	c.lock.Lock()
+	entry := c.typed[key]
+	c.lock.Unlock()
+	if entry != nil && c.fresh(imp, entry) {
+		imp.use(entry)
+		return entry.pkg, nil
+	}
+	stamp, err := imp.cfg.stamp(dir)
+	if err != nil {
+		return nil, err
+	}
+	pkg, err := imp.checkDir(path, dir)
+	if err != nil {
+		return nil, err
+	}
+	entry = &typedPackage{key: key, dir: dir, stamp: stamp, pkg: pkg}
+	for _, dep := range pkg.Imports() {
+		if depEntry, ok := imp.cached[dep]; ok {
+			entry.deps = append(entry.deps, depEntry)
+		}
+	}
+	imp.cached[pkg] = entry
+	imp.fresh[entry] = true
+	c.lock.Lock()
+	if c.typed == nil {
+		c.typed = make(map[string]*typedPackage)
+	}
+	c.typed[key] = entry
+	c.lock.Unlock()
+	return pkg, nil
+}
+
+// fresh checks that cached package and its dependencies are not changed and the same as packages
+// already imported by the importer (concurrent checks could replace them in cache)
+func (c *Cache) fresh(imp *typedImporter, entry *typedPackage) bool {
+	if ok, checked := imp.fresh[entry]; checked {
+		return ok
+	}
+	imp.fresh[entry] = true // dependencies can't refer back, but keep recursion finite for broken sources
+	c.lock.Lock()
+	current := c.typed[entry.key]
+	c.lock.Unlock()
+	ok := current == entry
+	if own := imp.packages[entry.dir]; ok && own != nil && own != entry.pkg {
+		ok = false
+	}
+	if ok {
+		stamp, err := imp.cfg.stamp(entry.dir)
+		ok = err == nil && stamp == entry.stamp
+	}
+	for _, dep := range entry.deps {
+		if !ok {
+			break
+		}
+		ok = c.fresh(imp, dep)
+	}
+	imp.fresh[entry] = ok
+	return ok
+}
+
+// packageName returns name of package by summary from memory or disk. Returns empty string if it's unknown or outdated
+func (c *Cache) packageName(cfg *Config, dir string) string {
+	stamp, err := cfg.stamp(dir)
+	if err != nil {
+		return ""
+	}
+	key := cfg.summaryKey(dir)
+	c.lock.Lock()
+	summary := c.names[key]
+	c.lock.Unlock()
+	if summary == nil {
+		summary = c.load(key)
+	}
+	if summary == nil || summary.Stamp != stamp {
+		return ""
+	}
+	return summary.Package
+}
+
+// save keeps summary in memory and on disk. Persisting is best-effort: errors are ignored
+func (c *Cache) save(key string, summary *packageSummary) {
+	c.lock.Lock()
+	if c.names == nil {
+		c.names = make(map[string]*packageSummary)
+	}
+	c.names[key] = summary
+	c.lock.Unlock()
+	if c.Dir == "" {
+		return
+	}
+	data, err := json.Marshal(summary)
+	if err != nil {
+		return
+	}
+	c.write(c.summaryFile(key, ".json"), data)
+}
+
+// saveSources persists sources of scanned package without bodies of functions. Packages with problems
+// (partial mode) are not persisted. Persisting is best-effort: errors are ignored
+func (c *Cache) saveSources(key, stamp string, files []*File) {
+	if c.Dir == "" {
+		return
+	}
+	stored := &packageSources{Stamp: stamp}
+	for _, file := range files {
+		if len(file.Diagnostics) > 0 || file.syntax == nil {
+			return
+		}
+		stored.Files = append(stored.Files, packageSource{Name: filepath.Base(file.location), Source: declarations(file)})
+	}
+	var data bytes.Buffer
+	if err := gob.NewEncoder(&data).Encode(stored); err != nil {
+		return
+	}
+	c.write(c.summaryFile(key, ".gob"), data.Bytes())
+}
+
+// loadSources reads persisted sources of package, returns nil if they don't exist or broken
+func (c *Cache) loadSources(key string) *packageSources {
+	if c.Dir == "" {
+		return nil
+	}
+	f, err := os.Open(c.summaryFile(key, ".gob"))
+	if err != nil {
+		return nil
+	}
+	defer f.Close()
+	var stored packageSources
+	if err := gob.NewDecoder(bufio.NewReader(f)).Decode(&stored); err != nil {
+		return nil
+	}
+	return &stored
+}
+
+// write replaces file in cache directory atomically
+func (c *Cache) write(filename string, data []byte) {
+	if err := os.MkdirAll(c.Dir, 0755); err != nil {
+		return
+	}
+	tmp, err := ioutil.TempFile(c.Dir, "cache-*.tmp")
+	if err != nil {
+		return
+	}
+	_, err = tmp.Write(data)
+	if closeErr := tmp.Close(); err == nil {
+		err = closeErr
+	}
+	if err == nil {
+		err = os.Rename(tmp.Name(), filename)
+	}
+	if err != nil {
+		os.Remove(tmp.Name())
+	}
+}
+
+// declarations returns source of file with bodies of functions and methods replaced by spaces,
+// so positions of declarations are kept
+func declarations(file *File) []byte {
+	src := []byte(file.Printer.Src)
+	for _, decl := range file.syntax.Decls {
+		fn, ok := decl.(*ast.FuncDecl)
+		if !ok || fn.Body == nil {
+			continue
+		}
+		from, to := file.Printer.offset(fn.Body.Lbrace)+1, file.Printer.offset(fn.Body.Rbrace)
+		for i := from; i < to && i < len(src); i++ {
+			if src[i] != '\n' {
+				src[i] = ' '
+			}
+		}
+	}
+	return src
+}
+
+// load reads persisted summary, returns nil if it doesn't exist or broken
+func (c *Cache) load(key string) *packageSummary {
+	if c.Dir == "" {
+		return nil
+	}
+	data, err := ioutil.ReadFile(c.summaryFile(key, ".json"))
+	if err != nil {
+		return nil
+	}
+	var summary packageSummary
+	if err := json.Unmarshal(data, &summary); err != nil {
+		return nil
+	}
+	c.lock.Lock()
+	if c.names == nil {
+		c.names = make(map[string]*packageSummary)
+	}
+	c.names[key] = &summary
+	c.lock.Unlock()
+	return &summary
+}
+
+func (c *Cache) summaryFile(key, ext string) string {
+	sum := sha256.Sum256([]byte(key))
+	return filepath.Join(c.Dir, hex.EncodeToString(sum[:])+ext)
+}
+
+// stamp identifies content of Go files in directory by names, sizes and modification times.
+// Files of overlay are identified by hash of content
+func (cfg *Config) stamp(dir string) (string, error) {
+	content, err := cfg.readDir(dir)
+	if err != nil {
+		return "", err
+	}
+	hash := sha256.New()
+	for _, info := range content {
+		name := info.Name()
+		if info.IsDir() || !strings.HasSuffix(name, ".go") {
+			continue
+		}
+		if data, ok := cfg.overlay(filepath.Join(dir, name)); ok {
+			fmt.Fprintf(hash, "%s overlay %x\n", name, sha256.Sum256(data))
+			continue
+		}
+		fmt.Fprintf(hash, "%s %d %d\n", name, info.Size(), info.ModTime().UnixNano())
+	}
+	return hex.EncodeToString(hash.Sum(nil)), nil
+}
+
+// summaryKey identifies directory with build context which selects files
+func (cfg *Config) summaryKey(dir string) string {
+	if abs, err := filepath.Abs(dir); err == nil {
+		dir = abs
+	}
+	ctx := cfg.build()
+	return fmt.Sprintf("%s\n%s/%s cgo=%v tags=%v", dir, ctx.GOOS, ctx.GOARCH, ctx.CgoEnabled, ctx.BuildTags)
+}
+
+// cacheKey identifies directory with options of config which affect scanned files and lookups from them.
+// Content of files (including overlay) is identified by stamp: changed package replaces cached one
+func (cfg *Config) cacheKey(dir string) string {
+	return fmt.Sprintf("%s\nresolver=%#v partial=%v annotations=%q",
+		cfg.summaryKey(dir), cfg.Resolver, cfg.Partial, cfg.AnnotationPrefixes)
+}
+
+// packageOf returns common package name of files or empty string
+func packageOf(files []*File) string {
+	var name string
+	for _, file := range files {
+		if name != "" && name != file.Package {
+			return ""
+		}
+		name = file.Package
+	}
+	return name
+}
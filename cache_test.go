@@ -0,0 +1,107 @@
+package atool
+
+import (
+	"github.com/alecthomas/assert"
+	"go/ast"
+	"go/types"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+)
+
+func TestCache(t *testing.T) {
+	dir, err := ioutil.TempDir("", "astools-cache")
+	assert.Nil(t, err)
+	defer os.RemoveAll(dir)
+
+	cfg := &Config{Cache: NewCache(dir)}
+	f, err := cfg.Scan("test/typed/typed.go")
+	assert.Nil(t, err)
+	link, err := f.ExtractTypeString("url.URL")
+	assert.Nil(t, err)
+	assert.Equal(t, "net/url", link.File.Import)
+
+	var wg sync.WaitGroup
+	found := make([]*Struct, 8)
+	for i := range found {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			other, err := cfg.Scan("test/typed/typed.go")
+			if err == nil {
+				found[i], _ = other.ExtractTypeString("url.URL")
+			}
+		}(i)
+	}
+	wg.Wait()
+	for _, st := range found {
+		assert.True(t, st == link, "imported package should be parsed once")
+	}
+
+	// names of packages are persisted
+	assert.Equal(t, "url", NewCache(dir).packageName(cfg, filepath.Dir(link.File.Location())))
+	assert.Equal(t, "", NewCache("").packageName(cfg, filepath.Dir(link.File.Location())))
+
+	// scanned packages are persisted without bodies of functions
+	next := &Config{Cache: NewCache(dir)}
+	f, err = next.Scan("test/typed/typed.go")
+	assert.Nil(t, err)
+	restored, err := f.ExtractTypeString("url.URL")
+	assert.Nil(t, err)
+	assert.True(t, restored != link)
+	assert.Equal(t, link.Position, restored.Position)
+	assert.Equal(t, link.File.Location(), restored.File.Location())
+	assert.Equal(t, len(link.Methods), len(restored.Methods))
+	for _, decl := range restored.File.syntax.Decls {
+		if fn, ok := decl.(*ast.FuncDecl); ok && fn.Body != nil {
+			assert.Len(t, fn.Body.List, 0)
+		}
+	}
+}
+
+func TestCache_Changed(t *testing.T) {
+	cache := NewCache("")
+	app := []byte("package app\n\nimport \"example.com/lib\"\n\ntype App struct{ Current lib.Item }\n")
+	scan := func(lib string) *Struct {
+		cfg := &Config{
+			Resolver: MapResolver{"example.com/lib": "test/virtual/lib"},
+			Overlay:  map[string][]byte{"test/virtual/lib/lib.go": []byte(lib)},
+			Cache:    cache,
+		}
+		f, err := cfg.ScanSource("test/virtual/app/app.go", app)
+		assert.Nil(t, err)
+		item, err := f.ExtractTypeString("lib.Item")
+		assert.Nil(t, err)
+		return item
+	}
+	v1 := scan("package lib\n\ntype Item struct{ Name string }\n")
+	assert.True(t, v1 == scan("package lib\n\ntype Item struct{ Name string }\n"))
+	// edits of scanned source don't invalidate imported packages
+	app = append(app, []byte("\ntype Other struct{ Previous lib.Item }\n")...)
+	assert.True(t, v1 == scan("package lib\n\ntype Item struct{ Name string }\n"))
+	v2 := scan("package lib\n\ntype Item struct{ Title string }\n")
+	assert.NotNil(t, v2.Field("Title"))
+	assert.Nil(t, v2.Field("Name"))
+	// changed package replaces outdated one
+	assert.Equal(t, 1, len(cache.packages))
+}
+
+func TestCache_Typed(t *testing.T) {
+	cache := NewCache("")
+	link := func() *types.Named {
+		cfg := &Config{Typed: true, Cache: cache}
+		pkg, err := cfg.ScanPackage("test/typed")
+		assert.Nil(t, err)
+		account := pkg.Struct("Account")
+		assert.NotNil(t, account)
+		named, ok := account.Field("Link").TypeOf().(*types.Pointer).Elem().(*types.Named)
+		assert.True(t, ok)
+		return named
+	}
+	first := link()
+	assert.Equal(t, "net/url", first.Obj().Pkg().Path())
+	// imported packages are type-checked once
+	assert.True(t, first.Obj().Pkg() == link().Obj().Pkg())
+}
@@ -2,10 +2,13 @@ package main
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
+	"errors"
 	"github.com/Masterminds/sprig"
 	"github.com/reddec/astools"
 	"github.com/reddec/symbols"
+	"go/build"
 	"gopkg.in/alecthomas/kingpin.v2"
 	"io"
 	"io/ioutil"
@@ -13,32 +16,74 @@ import (
 	"os"
 	"path"
 	"path/filepath"
+	"sort"
+	"strconv"
 	"strings"
 	"text/template"
 )
 
 func main() {
+	resolve := kingpin.Flag("resolve", "Resolve packages with import prefix from local directory (prefix=dir). Checked before lookup").Short('r').StringMap()
+	lookup := kingpin.Flag("lookup", "Lookup order of imported packages (comma separated): vendor, module, gopath, goroot").Default("vendor,module,gopath,goroot").String()
+	tags := kingpin.Flag("tags", "Build tags (comma separated) to select files").String()
+	goos := kingpin.Flag("goos", "Target OS to select files").Default(build.Default.GOOS).String()
+	goarch := kingpin.Flag("goarch", "Target architecture to select files").Default(build.Default.GOARCH).String()
+	typed := kingpin.Flag("typed", "Type-check packages by go/types: enables package paths, underlying types and assignability in templates").Bool()
+	annotationPrefixes := kingpin.Flag("annotation-prefix", "Parse only annotations with the prefix (ex: astools:, +rpc:). All annotations are parsed by default").Strings()
+	cgo := kingpin.Flag("cgo", "Include files with cgo").Default(strconv.FormatBool(build.Default.CgoEnabled)).Bool()
+	workers := kingpin.Flag("workers", "Number of packages scanned concurrently by patterns, 0 means number of CPUs").Default("0").Int()
+	cacheDir := kingpin.Flag("cache-dir", "Directory to persist scanned imported packages (ex: standard library) between runs. Changed packages are scanned again").String()
+
 	dump := kingpin.Command("dump", "Dump source AST to JSON")
-	dumpFilter := dump.Flag("filter", "Filter output (used name flag)").Short('f').Default("all").Enum("all", "struct", "interface", "value")
+	dumpFilter := dump.Flag("filter", "Filter output (used name flag)").Short('f').Default("all").Enum("all", "struct", "interface", "value", "func", "type", "enum")
 	dumpFilterName := dump.Flag("filter-name", "Filter name").Short('n').String()
-	dumpGoFile := dump.Arg("input-file", "Input .go file").Required().String()
+	dumpFilterAnnotation := dump.Flag("filter-annotation", "Keep only declarations with the annotation (ex: astools:gen)").String()
+	dumpDiagnostics := dump.Flag("diagnostics", "Scan sources with errors and dump only found problems").Bool()
+	dumpGoFile := dump.Arg("input-file", "Input .go file, package directory, import path, path relative to go.work or patterns (./..., ./api/...)").Required().Strings()
 
 	gen := kingpin.Command("gen", "Generate result base on template, env variables and source go file")
-	genGoFile := gen.Arg("input-file", "Input .go file").Required().String()
+	genGoFile := gen.Arg("input-file", "Input .go file, package directory, import path, path relative to go.work or pattern (./..., ./api/...)").Required().String()
 	genTemplFile := gen.Arg("template", "Go template file. Vars: .Env and .Go").Required().Strings()
 	genExt := gen.Flag("ext", "Remove extension for output files").Short('e').Bool()
 	genOutput := gen.Flag("out", "Output folder. If not specified - to stdout").Short('o').String()
 	genCopy := gen.Flag("copy", "Copy original file to output (if specified)").Short('c').Bool()
+	genFilterAnnotation := gen.Flag("filter-annotation", "Keep only declarations with the annotation (ex: astools:gen)").String()
 	indexSymbols := gen.Flag("index", "Index all symbols during generations (sym func)").Short('I').Bool()
 
-	switch kingpin.Parse() {
+	command := kingpin.Parse()
+	ctx := build.Default
+	ctx.GOOS, ctx.GOARCH, ctx.CgoEnabled = *goos, *goarch, *cgo
+	for _, tag := range strings.Split(*tags, ",") {
+		if tag = strings.TrimSpace(tag); tag != "" {
+			ctx.BuildTags = append(ctx.BuildTags, tag)
+		}
+	}
+	cfg, err := config(*resolve, *lookup, &ctx)
+	if err != nil {
+		log.Fatal("configure:", err)
+	}
+	cfg.Typed = *typed
+	cfg.AnnotationPrefixes = *annotationPrefixes
+	cfg.Cache = atool.NewCache(*cacheDir)
+	cfg.Workers = *workers
+	switch command {
 	case "dump":
-		data, err := atool.Scan(*dumpGoFile)
+		if *dumpDiagnostics && *dumpFilter != "all" {
+			log.Fatal("diagnostics can't be filtered: use --diagnostics without --filter")
+		}
+		cfg.Partial = *dumpDiagnostics
+		data, err := scan(cfg, *dumpGoFile...)
 		if err != nil {
 			log.Fatal("scan:", err)
 		}
+		if *dumpFilterAnnotation != "" {
+			data = withAnnotation(data, *dumpFilterAnnotation)
+		}
 
 		var res interface{} = data
+		if *dumpDiagnostics {
+			res = diagnostics(data)
+		}
 
 		switch *dumpFilter {
 		case "struct":
@@ -47,8 +92,13 @@ func main() {
 			res = data.Interface(*dumpFilterName)
 		case "value":
 			res = data.Value(*dumpFilterName)
+		case "func":
+			res = data.Func(*dumpFilterName)
+		case "type":
+			res = data.Type(*dumpFilterName)
+		case "enum":
+			res = data.Enum(*dumpFilterName)
 		case "all":
-			res = data
 		default:
 			log.Fatal("unknown filter mode:", *dumpFilter)
 		}
@@ -59,19 +109,24 @@ func main() {
 		}
 		os.Stdout.Write(dump)
 	case "gen":
-		data, err := atool.Scan(*genGoFile)
+		data, err := scan(cfg, *genGoFile)
 		if err != nil {
 			log.Fatal("scan:", err)
 		}
+		if *genFilterAnnotation != "" {
+			data = withAnnotation(data, *genFilterAnnotation)
+		}
+		files := sourceFiles(data)
 		funcs := sprig.TxtFuncMap()
+		funcs["typegraph"] = atool.NewTypeGraph
 		if *indexSymbols {
-			project, err := symbols.ProjectByDir(filepath.Dir(*genGoFile), 8192)
+			project, err := symbols.ProjectByDir(filepath.Dir(files[0].Location()), 8192)
 			if err != nil {
 				log.Fatal("index symbols: ", err)
 			}
 			var currentFile *symbols.File
 			for _, f := range project.Package.Files {
-				if filepath.Base(f.Filename) == filepath.Base(*genGoFile) {
+				if filepath.Base(f.Filename) == filepath.Base(files[0].Location()) {
 					currentFile = f
 					break
 				}
@@ -147,11 +202,109 @@ func main() {
 			}
 		}
 		if *genOutput != "" && *genCopy {
-			target := path.Join(*genOutput, filepath.Base(*genGoFile))
-			err = ioutil.WriteFile(target, []byte(data.Printer.Src), 0755)
-			if err != nil {
-				log.Fatal("copy to", target, ":", err)
+			for _, file := range files {
+				target := path.Join(*genOutput, filepath.Base(file.Location()))
+				err = ioutil.WriteFile(target, []byte(file.Printer.Src), 0755)
+				if err != nil {
+					log.Fatal("copy to", target, ":", err)
+				}
 			}
 		}
 	}
 }
+
+// source is a common lookup API of a single file and a whole package
+type source interface {
+	Struct(name string) *atool.Struct
+	Interface(name string) *atool.Interface
+	Value(name string) *atool.Value
+	Func(name string) *atool.Func
+	Type(name string) *atool.NamedType
+	Enum(name string) *atool.Enum
+}
+
+// scan reads a single file or, if the input is a directory or import path, all files of the package.
+// Multiple inputs or patterns with ... are scanned as list of packages
+func scan(cfg *atool.Config, inputs ...string) (source, error) {
+	if len(inputs) > 1 || strings.HasSuffix(inputs[0], "...") {
+		pkgs, err := cfg.ScanPatterns(context.Background(), ".", inputs...)
+		if err == nil && len(pkgs) == 0 {
+			err = errors.New("no packages matched " + strings.Join(inputs, " "))
+		}
+		return pkgs, err
+	}
+	input, err := atool.ResolvePackage(".", inputs[0])
+	if err != nil {
+		return nil, err
+	}
+	st, err := os.Stat(input)
+	if err != nil {
+		return nil, err
+	}
+	if st.IsDir() {
+		return cfg.ScanPackage(input)
+	}
+	return cfg.Scan(input)
+}
+
+// config builds resolver from custom prefixes (longest first) and built-in resolvers
+func config(resolve map[string]string, lookup string, buildContext *build.Context) (*atool.Config, error) {
+	var chain atool.ChainResolver
+	var prefixes []string
+	for prefix := range resolve {
+		prefixes = append(prefixes, prefix)
+	}
+	sort.Slice(prefixes, func(i, j int) bool { return len(prefixes[i]) > len(prefixes[j]) })
+	for _, prefix := range prefixes {
+		chain = append(chain, atool.DirResolver{Prefix: prefix, Dir: resolve[prefix]})
+	}
+	for _, name := range strings.Split(lookup, ",") {
+		switch strings.TrimSpace(name) {
+		case "vendor":
+			chain = append(chain, atool.VendorResolver{})
+		case "module":
+			chain = append(chain, atool.ModuleResolver{})
+		case "gopath":
+			chain = append(chain, atool.GopathResolver{})
+		case "goroot":
+			chain = append(chain, atool.GorootResolver{})
+		case "":
+		default:
+			return nil, errors.New("unknown lookup " + name)
+		}
+	}
+	return &atool.Config{Resolver: chain, Build: buildContext}, nil
+}
+
+// diagnostics returns problems of sources, never nil
+func diagnostics(data source) []*atool.Diagnostic {
+	res := []*atool.Diagnostic{}
+	switch v := data.(type) {
+	case *atool.Package:
+		return append(res, v.Diagnostics...)
+	case atool.Packages:
+		return append(res, v.Diagnostics()...)
+	}
+	return append(res, data.(*atool.File).Diagnostics...)
+}
+
+// withAnnotation keeps only declarations marked by the annotation
+func withAnnotation(data source, key string) source {
+	switch v := data.(type) {
+	case *atool.Package:
+		return v.WithAnnotation(key)
+	case atool.Packages:
+		return v.WithAnnotation(key)
+	}
+	return data.(*atool.File).WithAnnotation(key)
+}
+
+func sourceFiles(data source) []*atool.File {
+	switch v := data.(type) {
+	case *atool.Package:
+		return v.Files
+	case atool.Packages:
+		return v.Files()
+	}
+	return []*atool.File{data.(*atool.File)}
+}
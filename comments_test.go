@@ -0,0 +1,38 @@
+package atool
+
+import (
+	"github.com/alecthomas/assert"
+	"testing"
+)
+
+func TestComments(t *testing.T) {
+	f, err := Scan("test/comments/comments.go")
+	assert.Nil(t, err)
+
+	point := f.Struct("Point")
+	assert.Equal(t, "Point is a location\n", point.Doc)
+	assert.Equal(t, "point in space\n", point.LineComment)
+	assert.Equal(t, "X is horizontal\n", point.Field("X").Doc)
+	assert.Equal(t, "pixels\n", point.Field("X").LineComment)
+	assert.Equal(t, "", point.Field("Y").Doc)
+	assert.Equal(t, "pixels\n", point.Field("Y").LineComment)
+	assert.Equal(t, "X is horizontal\n\npixels\n", point.Field("X").Comment)
+
+	shape := f.Interface("Shape")
+	assert.Equal(t, "Shape could be drawn\n", shape.Doc)
+	assert.Equal(t, "Draw the shape\n", shape.Method("Draw").Doc)
+	assert.Equal(t, "may fail\n", shape.Method("Draw").LineComment)
+
+	size := f.Type("Size")
+	assert.Equal(t, "", size.Doc)
+	assert.Equal(t, "in pixels\n", size.LineComment)
+
+	assert.Equal(t, "Origin is a start of coordinates\n", f.Value("Origin").Doc)
+	assert.Equal(t, "zero point\n", f.Value("Origin").LineComment)
+	assert.Equal(t, "MaxWidth is a width limit\n", f.Value("MaxWidth").Doc)
+	assert.Equal(t, "", f.Value("MaxHeight").Doc)
+	assert.Equal(t, "height limit\n", f.Value("MaxHeight").LineComment)
+	assert.Equal(t, "height limit\n", f.Enum("Size").Value("MaxHeight").LineComment)
+
+	assert.Equal(t, "Render draws all shapes\n", f.Func("Render").Doc)
+}
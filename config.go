@@ -0,0 +1,100 @@
+package atool
+
+import (
+	"github.com/pkg/errors"
+	"go/build"
+	"go/token"
+	"path/filepath"
+	"strings"
+)
+
+// Config defines how sources are scanned and how imported packages are found.
+// Zero value is a valid configuration
+type Config struct {
+	Resolver Resolver       // finds imported packages, nil means DefaultResolver
+	Build    *build.Context // GOOS, GOARCH, tags and cgo to select files of directory, nil means build.Default
+	Typed    bool           // type-check scanned packages by go/types (imports are checked from sources)
+	Partial  bool           // return partially parsed (and checked) sources with diagnostics instead of errors
+	// Overlay replaces content of Go source files (path to content) for all reads: scanned files, siblings and imports.
+	// Files of overlay are added to their directories even if they don't exist on disk. Packages which exist only
+	// in overlay are resolved inside the module of importing file (go.mod is read from disk);
+	// vendor, GOPATH, GOROOT and custom resolvers see only directories on disk
+	Overlay map[string][]byte
+	// AnnotationPrefixes are markers of annotations in comments (ex: astools:, +rpc:). All markers are parsed if empty
+	AnnotationPrefixes []string
+	// Cache shares scanned and type-checked imported packages between scans, nil disables caching
+	Cache *Cache
+	// Workers limits number of packages scanned concurrently by ScanPatterns, 0 means number of CPUs
+	Workers int
+}
+
+var defaultConfig = &Config{}
+
+// Scan parses single file
+func (cfg *Config) Scan(filename string) (*File, error) {
+	tokens := token.NewFileSet()
+	file, err := cfg.scanFile(tokens, filename)
+	if err != nil {
+		return nil, err
+	}
+	if cfg.Typed {
+		if err := cfg.checkFile(tokens, file); err != nil {
+			return nil, errors.Wrapf(err, "check %v", filename)
+		}
+	}
+	return file, nil
+}
+
+func (cfg *Config) resolver() Resolver {
+	resolver := cfg.Resolver
+	if resolver == nil {
+		resolver = DefaultResolver()
+	}
+	if len(cfg.Overlay) > 0 {
+		return ChainResolver{resolver, overlayResolver{cfg: cfg}}
+	}
+	return resolver
+}
+
+// build returns copy of build context. Files are read with respect to overlay
+func (cfg *Config) build() *build.Context {
+	ctx := build.Default
+	if cfg.Build != nil {
+		ctx = *cfg.Build
+	}
+	if len(cfg.Overlay) > 0 {
+		ctx.OpenFile = cfg.openFile
+		ctx.ReadDir = cfg.readDir
+	}
+	return &ctx
+}
+
+// scanDir parses all non-test .go files of the directory matched by build context
+func (cfg *Config) scanDir(tokens *token.FileSet, dir string) ([]*File, error) {
+	content, err := cfg.readDir(dir)
+	if err != nil {
+		return nil, err
+	}
+	ctx := cfg.build()
+	var res []*File
+	for _, info := range content {
+		name := info.Name()
+		if info.IsDir() || !strings.HasSuffix(name, ".go") || strings.HasSuffix(name, "_test.go") {
+			continue
+		}
+		if ok, err := ctx.MatchFile(dir, name); err != nil {
+			return nil, errors.Wrapf(err, "match %v", name)
+		} else if !ok {
+			continue
+		}
+		file, err := cfg.scanFile(tokens, filepath.Join(dir, name))
+		if err != nil {
+			return nil, errors.Wrapf(err, "scan source %v", name)
+		}
+		if _, isCgo := file.Imports[`"C"`]; isCgo && !ctx.CgoEnabled {
+			continue
+		}
+		res = append(res, file)
+	}
+	return res, nil
+}
@@ -0,0 +1,43 @@
+package atool
+
+import (
+	"github.com/alecthomas/assert"
+	"go/build"
+	"testing"
+)
+
+func buildContext(goos string, cgo bool, tags ...string) *build.Context {
+	ctx := build.Default
+	ctx.GOOS = goos
+	ctx.CgoEnabled = cgo
+	ctx.BuildTags = tags
+	return &ctx
+}
+
+func TestConfig_Build(t *testing.T) {
+	linux := &Config{Build: buildContext("linux", false)}
+	pkg, err := linux.ScanPackage("test/testdata/build")
+	assert.Nil(t, err)
+	assert.Len(t, pkg.Files, 2)
+	assert.NotNil(t, pkg.Struct("Platform").Field("Linux"))
+	assert.Nil(t, pkg.Struct("Extra"))
+	assert.Nil(t, pkg.Struct("Native"))
+
+	windows := &Config{Build: buildContext("windows", true, "extra")}
+	pkg, err = windows.ScanPackage("test/testdata/build")
+	assert.Nil(t, err)
+	assert.Len(t, pkg.Files, 4)
+	assert.NotNil(t, pkg.Struct("Platform").Field("Windows"))
+	assert.NotNil(t, pkg.Struct("Extra"))
+	assert.NotNil(t, pkg.Struct("Native"))
+
+	// siblings and imports are selected by the same context
+	f, err := windows.Scan("test/testdata/build/common.go")
+	assert.Nil(t, err)
+	st, err := f.ExtractTypeString("Platform")
+	assert.Nil(t, err)
+	assert.NotNil(t, st.Field("Windows"))
+	st, err = f.ExtractTypeString("url.URL")
+	assert.Nil(t, err)
+	assert.Equal(t, "net/url", st.File.Import)
+}
@@ -0,0 +1,339 @@
+package atool
+
+import (
+	"encoding/json"
+	"go/ast"
+	"go/constant"
+	"go/token"
+)
+
+// constEntry is a single named constant with implicit repetition of type and expression applied
+type constEntry struct {
+	Name    *ast.Ident
+	Type    ast.Expr // explicit or repeated from previous spec
+	Value   ast.Expr // explicit or repeated from previous spec
+	Iota    int
+	Spec    *ast.ValueSpec
+	Decl    *ast.GenDecl
+	printer *Printer
+}
+
+// constEntries expands const declaration: every name gets own entry, specs without values repeat previous ones
+func constEntries(printer *Printer, decl *ast.GenDecl) []*constEntry {
+	if decl.Tok != token.CONST {
+		return nil
+	}
+	var res []*constEntry
+	var lastType ast.Expr
+	var lastValues []ast.Expr
+	for i, spec := range decl.Specs {
+		vs, ok := spec.(*ast.ValueSpec)
+		if !ok {
+			continue
+		}
+		if vs.Type != nil || len(vs.Values) > 0 {
+			lastType = vs.Type
+			lastValues = vs.Values
+		}
+		for j, name := range vs.Names {
+			entry := &constEntry{Name: name, Type: lastType, Iota: i, Spec: vs, Decl: decl, printer: printer}
+			if j < len(lastValues) {
+				entry.Value = lastValues[j]
+			}
+			res = append(res, entry)
+		}
+	}
+	return res
+}
+
+// typeName returns name of local named type of constant or empty string for untyped constants and predeclared types
+func (c *constEntry) typeName() string {
+	if ident, ok := c.Type.(*ast.Ident); ok {
+		if isPredeclared(ident.Name) {
+			return ""
+		}
+		return ident.Name
+	}
+	if c.Type != nil {
+		return ""
+	}
+	// typed by conversion: A = Status(1)
+	if call, ok := c.Value.(*ast.CallExpr); ok && len(call.Args) == 1 {
+		if ident, ok := call.Fun.(*ast.Ident); ok && !isPredeclared(ident.Name) && !isBuiltinFunc(ident.Name) {
+			return ident.Name
+		}
+	}
+	return ""
+}
+
+// constEvaluator computes values of constants by go/constant. Constants could refer each other in any order.
+type constEvaluator struct {
+	entries map[string]*constEntry
+	values  map[string]constant.Value
+	active  map[string]bool // cycle guard
+}
+
+func newConstEvaluator(entries []*constEntry) *constEvaluator {
+	ev := &constEvaluator{
+		entries: make(map[string]*constEntry),
+		values:  make(map[string]constant.Value),
+		active:  make(map[string]bool),
+	}
+	for _, e := range entries {
+		ev.entries[e.Name.Name] = e
+	}
+	return ev
+}
+
+// Value of constant by name. Returns unknown value if it can't be evaluated
+func (ev *constEvaluator) Value(name string) constant.Value {
+	if v, ok := ev.values[name]; ok {
+		return v
+	}
+	entry, ok := ev.entries[name]
+	if !ok || ev.active[name] {
+		return constant.MakeUnknown()
+	}
+	ev.active[name] = true
+	v := ev.eval(entry.Value, entry.Iota)
+	if entry.Type != nil {
+		v = convert(v, entry.Type)
+	}
+	delete(ev.active, name)
+	ev.values[name] = v
+	return v
+}
+
+func (ev *constEvaluator) eval(expr ast.Expr, iota int) (res constant.Value) {
+	defer func() {
+		// go/constant panics on operands of mismatched kinds
+		if recover() != nil {
+			res = constant.MakeUnknown()
+		}
+	}()
+	switch v := expr.(type) {
+	case *ast.BasicLit:
+		return constant.MakeFromLiteral(v.Value, v.Kind, 0)
+	case *ast.Ident:
+		switch v.Name {
+		case "iota":
+			return constant.MakeInt64(int64(iota))
+		case "true":
+			return constant.MakeBool(true)
+		case "false":
+			return constant.MakeBool(false)
+		}
+		return ev.Value(v.Name)
+	case *ast.ParenExpr:
+		return ev.eval(v.X, iota)
+	case *ast.UnaryExpr:
+		x := ev.eval(v.X, iota)
+		if x.Kind() == constant.Unknown {
+			return x
+		}
+		var prec uint
+		if call, ok := v.X.(*ast.CallExpr); ok {
+			prec = unsignedSize(call.Fun) // ^uint8(0) is 255, not -1
+		}
+		return constant.UnaryOp(v.Op, x, prec)
+	case *ast.BinaryExpr:
+		x, y := ev.eval(v.X, iota), ev.eval(v.Y, iota)
+		if x.Kind() == constant.Unknown || y.Kind() == constant.Unknown {
+			return constant.MakeUnknown()
+		}
+		switch v.Op {
+		case token.SHL, token.SHR:
+			s, ok := constant.Uint64Val(constant.ToInt(y))
+			if !ok {
+				return constant.MakeUnknown()
+			}
+			return constant.Shift(constant.ToInt(x), v.Op, uint(s))
+		case token.EQL, token.NEQ, token.LSS, token.LEQ, token.GTR, token.GEQ:
+			return constant.MakeBool(constant.Compare(x, v.Op, y))
+		case token.QUO:
+			if y.Kind() != constant.Bool && constant.Sign(y) == 0 {
+				return constant.MakeUnknown()
+			}
+			if x.Kind() == constant.Int && y.Kind() == constant.Int {
+				return constant.BinaryOp(x, token.QUO_ASSIGN, y) // integer division
+			}
+		case token.REM:
+			if constant.Sign(y) == 0 {
+				return constant.MakeUnknown()
+			}
+		}
+		return constant.BinaryOp(x, v.Op, y)
+	case *ast.CallExpr:
+		if len(v.Args) != 1 {
+			return constant.MakeUnknown()
+		}
+		x := ev.eval(v.Args[0], iota)
+		if ident, ok := v.Fun.(*ast.Ident); ok && ident.Name == "len" {
+			if x.Kind() == constant.String {
+				return constant.MakeInt64(int64(len(constant.StringVal(x))))
+			}
+			return constant.MakeUnknown()
+		}
+		return convert(x, v.Fun)
+	}
+	return constant.MakeUnknown()
+}
+
+// convert applies conversion to the predeclared type. Named types keep value as is.
+func convert(x constant.Value, tp ast.Expr) constant.Value {
+	ident, ok := tp.(*ast.Ident)
+	if !ok || x.Kind() == constant.Unknown {
+		return x
+	}
+	switch ident.Name {
+	case "int", "int8", "int16", "int32", "int64", "rune",
+		"uint", "uint8", "uint16", "uint32", "uint64", "uintptr", "byte":
+		return constant.ToInt(x)
+	case "float32", "float64":
+		return constant.ToFloat(x)
+	case "complex64", "complex128":
+		return constant.ToComplex(x)
+	case "string":
+		if x.Kind() == constant.Int {
+			if r, ok := constant.Int64Val(x); ok {
+				return constant.MakeString(string(rune(r)))
+			}
+		}
+	}
+	return x
+}
+
+// unsignedSize returns size in bits of unsigned predeclared type or 0
+func unsignedSize(tp ast.Expr) uint {
+	ident, ok := tp.(*ast.Ident)
+	if !ok {
+		return 0
+	}
+	switch ident.Name {
+	case "uint8", "byte":
+		return 8
+	case "uint16":
+		return 16
+	case "uint32":
+		return 32
+	case "uint", "uint64", "uintptr":
+		return 64
+	}
+	return 0
+}
+
+// evaluateValues computes values of constants
+func evaluateValues(values []*Value, entries []*constEntry) {
+	ev := newConstEvaluator(entries)
+	for _, val := range values {
+		if val.Kind == Const {
+			val.Evaluated = ev.Value(val.Name)
+		}
+	}
+}
+
+func isBuiltinFunc(name string) bool {
+	switch name {
+	case "len", "cap", "real", "imag", "complex", "min", "max", "new", "make", "append":
+		return true
+	}
+	return false
+}
+
+// Enum is a named type and its typed constants in declaration order
+type Enum struct {
+	Name     string
+	Comment  string       `json:",omitempty"`
+	Values   []*EnumValue `json:",omitempty"`
+	Type     *NamedType   `json:"-"`          // declaration of the type if found
+	Position *Position    `json:",omitempty"` // position of the type declaration if found
+}
+
+func (e *Enum) Value(name string) *EnumValue {
+	for _, v := range e.Values {
+		if v.Name == name {
+			return v
+		}
+	}
+	return nil
+}
+
+// EnumValue is a constant of enum with evaluated value
+type EnumValue struct {
+	Name        string
+	Comment     string         `json:",omitempty"` // combined view of all comments
+	Doc         string         `json:",omitempty"`
+	LineComment string         `json:",omitempty"`
+	Annotations Annotations    `json:",omitempty"`
+	Value       constant.Value `json:"-"`
+	Position    *Position
+}
+
+// GolangValue returns evaluated value as Go literal (ex: 1, "abc") or empty string if value is unknown
+func (v *EnumValue) GolangValue() string {
+	if v.Value == nil || v.Value.Kind() == constant.Unknown {
+		return ""
+	}
+	return v.Value.ExactString()
+}
+
+// Int returns integer value of constant if possible
+func (v *EnumValue) Int() (int64, bool) {
+	if v.Value == nil || v.Value.Kind() != constant.Int {
+		return 0, false
+	}
+	return constant.Int64Val(v.Value)
+}
+
+func (v *EnumValue) MarshalJSON() ([]byte, error) {
+	return json.Marshal(&struct {
+		Name        string
+		Comment     string      `json:",omitempty"`
+		Doc         string      `json:",omitempty"`
+		LineComment string      `json:",omitempty"`
+		Annotations Annotations `json:",omitempty"`
+		GolangValue string
+		Position    *Position `json:",omitempty"`
+	}{
+		Name:        v.Name,
+		Comment:     v.Comment,
+		Doc:         v.Doc,
+		LineComment: v.LineComment,
+		Annotations: v.Annotations,
+		GolangValue: v.GolangValue(),
+		Position:    v.Position,
+	})
+}
+
+// getEnums groups typed constants by type name in order of first appearance
+func getEnums(entries []*constEntry, lookup func(name string) *NamedType) []*Enum {
+	ev := newConstEvaluator(entries)
+	var res []*Enum
+	index := make(map[string]*Enum)
+	for _, entry := range entries {
+		tp := entry.typeName()
+		if tp == "" || entry.Name.Name == "_" {
+			continue
+		}
+		enum, ok := index[tp]
+		if !ok {
+			enum = &Enum{Name: tp, Type: lookup(tp)}
+			if enum.Type != nil {
+				enum.Comment = enum.Type.Comment
+				enum.Position = enum.Type.Position
+			}
+			index[tp] = enum
+			res = append(res, enum)
+		}
+		enum.Values = append(enum.Values, &EnumValue{
+			Name:        entry.Name.Name,
+			Comment:     joinComments(entry.printer.CommentMap[entry.Spec]),
+			Doc:         specDoc(entry.Decl, entry.Spec.Doc).Text(),
+			LineComment: entry.Spec.Comment.Text(),
+			Annotations: entry.printer.annotations(specDoc(entry.Decl, entry.Spec.Doc), entry.Spec.Comment),
+			Value:       ev.Value(entry.Name.Name),
+			Position:    entry.printer.position(entry.Name, entry.Spec),
+		})
+	}
+	return res
+}
@@ -0,0 +1,94 @@
+package atool
+
+import (
+	"encoding/json"
+	"github.com/alecthomas/assert"
+	"testing"
+)
+
+func TestFile_Enums(t *testing.T) {
+	f, err := Scan("test/enums/enums.go")
+	assert.Nil(t, err)
+	assert.Len(t, f.Enums, 3)
+
+	status := f.Enum("Status")
+	assert.Equal(t, f.Type("Status"), status.Type)
+	assert.Equal(t, "Status of task\n", status.Comment)
+	var names, values []string
+	for _, v := range status.Values {
+		names = append(names, v.Name)
+		values = append(values, v.GolangValue())
+	}
+	assert.Equal(t, []string{"Pending", "Running", "Done"}, names)
+	assert.Equal(t, []string{"0", "1", "3"}, values)
+	assert.Equal(t, "Pending is initial status\n", status.Value("Pending").Comment)
+	assert.Equal(t, "in progress\n", status.Value("Running").Comment)
+	done, ok := status.Value("Done").Int()
+	assert.True(t, ok)
+	assert.Equal(t, int64(3), done)
+
+	level := f.Enum("Level")
+	assert.Equal(t, "4", level.Value("High").GolangValue())
+
+	color := f.Enum("Color")
+	assert.Equal(t, `"red"`, color.Value("Red").GolangValue())
+	assert.Equal(t, `"green"`, color.Value("Green").GolangValue())
+
+	data, err := json.Marshal(status.Values[1])
+	assert.Nil(t, err)
+	assert.Equal(t, `{"Name":"Running","Comment":"in progress\n","LineComment":"in progress\n","GolangValue":"1",`+
+		`"Position":{"Filename":"test/enums/enums.go","Line":9,"Column":2,"Offset":112,"End":119}}`, string(data))
+}
+
+func TestConstEvaluator(t *testing.T) {
+	f, err := Scan("test/enums/enums.go")
+	assert.Nil(t, err)
+	ev := newConstEvaluator(f.consts)
+	assert.Equal(t, "1024", ev.Value("KB").ExactString())
+	assert.Equal(t, "1048576", ev.Value("MB").ExactString())
+	assert.Equal(t, "255", ev.Value("Mask").ExactString())
+	assert.Equal(t, "349525", ev.Value("Half").ExactString())
+	assert.Equal(t, "unknown", ev.Value("Unknown").String())
+}
+
+func TestFile_Values(t *testing.T) {
+	f, err := Scan("test/values/values.go")
+	assert.Nil(t, err)
+	var names []string
+	for _, v := range f.Values {
+		names = append(names, v.Name)
+	}
+	assert.Equal(t, []string{"A", "B", "X", "Y", "Z", "Name", "Count", "Total", "Parsed", "Error"}, names)
+
+	b := f.Value("B")
+	assert.True(t, b.IsConst())
+	assert.Equal(t, "2", b.GolangValue())
+	assert.Equal(t, "2", b.EvaluatedValue())
+
+	y := f.Value("Y")
+	assert.Equal(t, Const, y.Kind)
+	assert.Equal(t, "float64", y.GolangType())
+	assert.Equal(t, "1.5", y.GolangValue())
+	assert.Equal(t, "3/2", y.EvaluatedValue())
+	assert.Equal(t, "3", f.Value("Z").EvaluatedValue())
+
+	total := f.Value("Total")
+	assert.Equal(t, Var, total.Kind)
+	assert.Equal(t, "int", total.GolangType())
+	assert.Equal(t, "", total.GolangValue())
+	assert.Equal(t, `strconv.Atoi("42")`, f.Value("Error").GolangValue())
+	assert.Equal(t, "", f.Value("Name").EvaluatedValue())
+
+	data, err := json.Marshal(b)
+	assert.Nil(t, err)
+	assert.Equal(t, `{"Name":"B","Kind":"const","GolangType":"","GolangValue":"2","EvaluatedValue":"2","IsError":false,`+
+		`"Position":{"Filename":"test/values/values.go","Line":7,"Column":10,"Offset":45,"End":56}}`, string(data))
+
+	other, err := Scan("test/values/other.go")
+	assert.Nil(t, err)
+	assert.Equal(t, "", other.Value("C").EvaluatedValue())
+
+	pkg, err := ScanPackage("test/values")
+	assert.Nil(t, err)
+	assert.Equal(t, "13", pkg.Value("C").EvaluatedValue())
+}
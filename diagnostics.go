@@ -0,0 +1,56 @@
+package atool
+
+import (
+	"go/scanner"
+	"go/token"
+	"go/types"
+)
+
+// Severity of diagnostic
+type Severity string
+
+const (
+	SeverityError   Severity = "error"
+	SeverityWarning Severity = "warning"
+)
+
+// Diagnostic is a problem of source found by parser or type checker
+type Diagnostic struct {
+	Position Position
+	Message  string
+	Severity Severity
+}
+
+func (d *Diagnostic) String() string {
+	return d.Position.String() + ": " + string(d.Severity) + ": " + d.Message
+}
+
+// parseDiagnostics converts errors of parser
+func parseDiagnostics(filename string, err error) []*Diagnostic {
+	list, ok := err.(scanner.ErrorList)
+	if !ok {
+		return []*Diagnostic{{Position: Position{Filename: filename}, Message: err.Error(), Severity: SeverityError}}
+	}
+	var res []*Diagnostic
+	for _, item := range list {
+		res = append(res, &Diagnostic{Position: diagnosticPosition(item.Pos), Message: item.Msg, Severity: SeverityError})
+	}
+	return res
+}
+
+// typeDiagnostic converts error of type checker. Soft errors (ex: unused variable) are warnings
+func typeDiagnostic(err error) *Diagnostic {
+	typeErr, ok := err.(types.Error)
+	if !ok {
+		return &Diagnostic{Message: err.Error(), Severity: SeverityError}
+	}
+	res := &Diagnostic{Position: diagnosticPosition(typeErr.Fset.Position(typeErr.Pos)), Message: typeErr.Msg, Severity: SeverityError}
+	if typeErr.Soft {
+		res.Severity = SeverityWarning
+	}
+	return res
+}
+
+func diagnosticPosition(pos token.Position) Position {
+	return Position{Filename: pos.Filename, Line: pos.Line, Column: pos.Column, Offset: pos.Offset, End: pos.Offset}
+}
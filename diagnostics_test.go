@@ -0,0 +1,46 @@
+package atool
+
+import (
+	"encoding/json"
+	"github.com/alecthomas/assert"
+	"testing"
+)
+
+func TestConfig_Partial(t *testing.T) {
+	_, err := Scan("test/testdata/broken/broken.go")
+	assert.NotNil(t, err)
+
+	cfg := &Config{Partial: true}
+	f, err := cfg.Scan("test/testdata/broken/broken.go")
+	assert.Nil(t, err)
+	assert.NotNil(t, f.Struct("Valid").Field("Name"))
+	assert.NotNil(t, f.Struct("Broken").Field("Field"))
+	assert.True(t, len(f.Diagnostics) > 0)
+	first := f.Diagnostics[0]
+	assert.Equal(t, SeverityError, first.Severity)
+	assert.Equal(t, "test/testdata/broken/broken.go", first.Position.Filename)
+	assert.Equal(t, 10, first.Position.Line)
+	assert.NotEqual(t, "", first.Message)
+
+	pkg, err := cfg.ScanPackage("test/testdata/broken")
+	assert.Nil(t, err)
+	assert.Equal(t, len(f.Diagnostics), len(pkg.Diagnostics))
+
+	data, err := json.Marshal(first)
+	assert.Nil(t, err)
+	assert.Contains(t, string(data), `"Severity":"error"`)
+}
+
+func TestConfig_PartialTyped(t *testing.T) {
+	_, err := (&Config{Typed: true}).ScanPackage("test/testdata/mistyped")
+	assert.NotNil(t, err)
+
+	pkg, err := (&Config{Typed: true, Partial: true}).ScanPackage("test/testdata/mistyped")
+	assert.Nil(t, err)
+	assert.NotNil(t, pkg.TypesPackage)
+	assert.Len(t, pkg.Diagnostics, 2)
+	assert.Equal(t, 4, pkg.Diagnostics[0].Position.Line)
+	assert.Equal(t, 7, pkg.Diagnostics[1].Position.Line)
+	assert.Equal(t, pkg.Diagnostics, pkg.Files[0].Diagnostics)
+	assert.Equal(t, "int", pkg.Value("Limit").Ref().Name)
+}
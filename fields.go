@@ -0,0 +1,144 @@
+package atool
+
+import (
+	"github.com/pkg/errors"
+	"go/ast"
+)
+
+// EmbeddedType returns type name of embedded field without pointer and type arguments (ex: pkg.Base) or empty string
+func (arg *Arg) EmbeddedType() string {
+	if !arg.IsEmbedded {
+		return ""
+	}
+	return arg.printer.ToString(typeName(arg.Type))
+}
+
+// AllFields returns own fields and fields promoted from embedded structs (local and imported).
+// Shadowing follows Go rules: field or method on lower depth hides deeper fields with the same name,
+// and fields with the same name as other field or method on the same depth are ambiguous and not promoted.
+// Methods are taken from Methods of structs and named types, so methods of explicitly scanned file
+// are limited to the file (see Scan)
+func (s *Struct) AllFields() ([]*Arg, error) {
+	var res []*Arg
+	hidden := make(map[string]bool)
+	visited := make(map[*Struct]bool)
+	level := []*embeddedType{{fields: s, methods: s.Methods}}
+	for len(level) > 0 {
+		var next []*embeddedType
+		var fields []*Arg
+		count := make(map[string]int)
+		for _, tp := range level {
+			if tp.fields != nil {
+				visited[tp.fields] = true
+			}
+		}
+		// same struct could be embedded several times on one depth - fields of such struct are ambiguous
+		for _, tp := range level {
+			for _, m := range tp.methods {
+				count[m.Name]++
+			}
+			if tp.fields == nil {
+				continue
+			}
+			st := tp.fields
+			for _, field := range st.Fields {
+				if field.Name == "_" {
+					continue
+				}
+				count[field.Name]++
+				if !hidden[field.Name] {
+					fields = append(fields, field)
+				}
+				if !field.IsEmbedded {
+					continue
+				}
+				embedded, err := st.embeddedType(field)
+				if err != nil {
+					return nil, err
+				}
+				if embedded.fields != nil && visited[embedded.fields] {
+					embedded.fields = nil
+				}
+				next = append(next, embedded)
+			}
+		}
+		for _, field := range fields {
+			if count[field.Name] == 1 {
+				res = append(res, field)
+			}
+		}
+		for name := range count {
+			hidden[name] = true
+		}
+		level = next
+	}
+	return res, nil
+}
+
+// embeddedType is a type on some depth of embedding: struct of its fields and its methods
+type embeddedType struct {
+	fields  *Struct // nil for types without fields
+	methods []*Method
+}
+
+// embeddedType resolves fields and methods of embedded field. Embedded interfaces and named non-struct types
+// (ex: type Counter int, time.Duration) have only methods
+func (s *Struct) embeddedType(field *Arg) (*embeddedType, error) {
+	if s.File == nil {
+		return nil, errors.Errorf("resolve embedded %v of %v: unknown source file", field.Name, s.Name)
+	}
+	name := field.EmbeddedType()
+	named, err := s.File.ExtractNamedString(name)
+	if err != nil {
+		return nil, errors.Wrapf(err, "resolve embedded %v of %v", name, s.Name)
+	}
+	res := &embeddedType{fields: namedStruct(named)}
+	switch v := named.(type) {
+	case *Struct:
+		res.methods = v.Methods
+	case *NamedType:
+		res.methods = v.Methods
+		if v.IsAlias && res.fields != nil {
+			res.methods = res.fields.Methods
+		}
+	case *Interface:
+		res.methods, err = v.AllMethods()
+		if err != nil {
+			return nil, errors.Wrapf(err, "resolve embedded %v of %v", name, s.Name)
+		}
+	}
+	return res, nil
+}
+
+// namedStruct returns struct of declared type: struct itself or struct which is a base of named type
+// (type Derived Base, type Alias = Base). Returns nil for interfaces and other types
+func namedStruct(named Named) *Struct {
+	for i := 0; i < maxUnderlyingDepth; i++ {
+		switch v := named.(type) {
+		case *Struct:
+			return v
+		case *NamedType:
+			base := typeName(v.Type)
+			switch ident := base.(type) {
+			case *ast.Ident:
+				if _, predeclared := predeclaredKinds[ident.Name]; predeclared {
+					return nil
+				}
+			case *ast.SelectorExpr:
+			default:
+				return nil
+			}
+			if v.File == nil {
+				return nil
+			}
+			next, err := v.File.ExtractNamedString(v.printer.ToString(base))
+			if err != nil {
+				return nil
+			}
+			named = next
+		default:
+			return nil
+		}
+	}
+	return nil
+}
@@ -0,0 +1,53 @@
+package atool
+
+import (
+	"github.com/alecthomas/assert"
+	"testing"
+)
+
+func fieldNames(fields []*Arg) []string {
+	var names []string
+	for _, f := range fields {
+		names = append(names, f.Name)
+	}
+	return names
+}
+
+func TestStruct_AllFields(t *testing.T) {
+	f, err := Scan("test/embedding/embedding.go")
+	assert.Nil(t, err)
+
+	entity := f.Struct("Entity")
+	assert.Equal(t, []string{"Base", "Meta", "Point", "Name"}, fieldNames(entity.Fields))
+	assert.True(t, entity.Field("Base").IsEmbedded)
+	assert.Equal(t, "Base", entity.Field("Base").EmbeddedType())
+	assert.Equal(t, "image.Point", entity.Field("Point").EmbeddedType())
+	assert.Equal(t, "`json:\"meta\"`", entity.Field("Meta").Tag)
+	assert.False(t, entity.Field("Name").IsEmbedded)
+	assert.Equal(t, "", entity.Field("Name").EmbeddedType())
+
+	all, err := entity.AllFields()
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"Base", "Meta", "Point", "Name", "ID", "Tags", "X", "Y"}, fieldNames(all))
+	assert.Equal(t, entity.Field("Name"), all[3])
+
+	all, err = f.Struct("Ambiguous").AllFields()
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"Base", "Meta", "error", "ID", "Tags"}, fieldNames(all))
+
+	all, err = f.Struct("Twice").AllFields()
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"Entity", "Ambiguous", "Point", "Name", "error", "X", "Y"}, fieldNames(all))
+
+	all, err = f.Struct("Wrapped").AllFields()
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"Counter", "Duration", "Derived", "Name", "Tags"}, fieldNames(all))
+
+	// methods hide promoted fields
+	all, err = f.Struct("Labeled").AllFields()
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"Base", "ID"}, fieldNames(all))
+	all, err = f.Struct("Page").AllFields()
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"Titled", "Meta", "Name"}, fieldNames(all))
+}
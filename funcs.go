@@ -0,0 +1,139 @@
+package atool
+
+import (
+	"go/ast"
+)
+
+// Func is a top-level function declaration (without receiver)
+type Func struct {
+	Method
+	TypeParams []*Arg        `json:",omitempty"`
+	Definition *ast.FuncDecl `json:"-"`
+	File       *File         `json:"-"`
+}
+
+// IsGeneric checks that function has type parameters
+func (fn *Func) IsGeneric() bool {
+	return len(fn.TypeParams) > 0
+}
+
+func Funcs(printer *Printer, decls ...ast.Node) []*Func {
+	var res []*Func
+	for _, node := range decls {
+		decl, ok := node.(*ast.FuncDecl)
+		if !ok || decl.Recv != nil {
+			continue
+		}
+		fn := &Func{
+			Method:     *asMethod(decl.Name, joinComments(printer.CommentMap[decl]), decl.Type, printer),
+			Definition: decl,
+		}
+		fn.TypeParams = getTypeParams(printer, decl.Type.TypeParams)
+		fn.Doc = decl.Doc.Text()
+		fn.Annotations = printer.annotations(decl.Doc)
+		fn.Position = printer.position(decl.Name, decl)
+		res = append(res, fn)
+	}
+	return res
+}
+
+// Methods returns declared functions with receivers
+func Methods(printer *Printer, decls ...ast.Node) []*Method {
+	var res []*Method
+	for _, node := range decls {
+		decl, ok := node.(*ast.FuncDecl)
+		if !ok || decl.Recv == nil || len(decl.Recv.List) == 0 {
+			continue
+		}
+		method := asMethod(decl.Name, joinComments(printer.CommentMap[decl]), decl.Type, printer)
+		method.Receiver = getArgs(printer, decl.Recv.List)[0]
+		method.Doc = decl.Doc.Text()
+		method.Annotations = printer.annotations(decl.Doc)
+		method.Position = printer.position(decl.Name, decl)
+		res = append(res, method)
+	}
+	return res
+}
+
+// ReceiverType returns name of receiver type without pointer and type parameters or empty string for functions
+func (m *Method) ReceiverType() string {
+	if m.Receiver == nil {
+		return ""
+	}
+	if ident, ok := typeName(m.Receiver.Type).(*ast.Ident); ok {
+		return ident.Name
+	}
+	return ""
+}
+
+// HasPointerReceiver checks that method declared with pointer receiver
+func (m *Method) HasPointerReceiver() bool {
+	return m.Receiver != nil && m.Receiver.IsPointer()
+}
+
+// attachMethods replaces methods of the structs and named types by matched (by receiver type) methods
+func attachMethods(structs []*Struct, types []*NamedType, methods []*Method) {
+	for _, st := range structs {
+		st.Methods = receiverMethods(st.Name, methods)
+	}
+	for _, tp := range types {
+		tp.Methods = receiverMethods(tp.Name, methods)
+	}
+}
+
+// attachPackageMethods attaches methods declared in any file of the package to structs and types of all files
+// except the skipped one: explicitly scanned file keeps only methods declared in it
+func attachPackageMethods(files []*File, skip *File) {
+	byPackage := make(map[string][]*File)
+	for _, file := range files {
+		byPackage[file.Package] = append(byPackage[file.Package], file)
+	}
+	for _, group := range byPackage {
+		var structs []*Struct
+		var types []*NamedType
+		var methods []*Method
+		for _, file := range group {
+			if file != skip {
+				structs = append(structs, file.Structs...)
+				types = append(types, file.Types...)
+			}
+			methods = append(methods, file.methods...)
+		}
+		attachMethods(structs, types, methods)
+	}
+}
+
+func receiverMethods(name string, methods []*Method) []*Method {
+	var res []*Method
+	for _, m := range methods {
+		if m.ReceiverType() == name {
+			res = append(res, m)
+		}
+	}
+	return res
+}
+
+func (s *Struct) Method(name string) *Method {
+	for _, m := range s.Methods {
+		if m.Name == name {
+			return m
+		}
+	}
+	return nil
+}
+
+// ValueMethodSet returns methods with value receivers - method set of type T
+func (s *Struct) ValueMethodSet() []*Method {
+	var res []*Method
+	for _, m := range s.Methods {
+		if !m.HasPointerReceiver() {
+			res = append(res, m)
+		}
+	}
+	return res
+}
+
+// PointerMethodSet returns methods with value and pointer receivers - method set of type *T
+func (s *Struct) PointerMethodSet() []*Method {
+	return s.Methods
+}
@@ -0,0 +1,82 @@
+package atool
+
+import (
+	"github.com/alecthomas/assert"
+	"testing"
+)
+
+func TestFile_Func(t *testing.T) {
+	f, err := Scan("test/sample.go")
+	assert.Nil(t, err)
+	assert.Len(t, f.Funcs, 1)
+	fn := f.Func("NewRocket")
+	assert.NotNil(t, fn)
+	assert.Equal(t, "NewRocket creates rocket with the default tank\n", fn.Comment)
+	assert.Len(t, fn.In, 2)
+	assert.Equal(t, "name", fn.In[0].Name)
+	assert.Equal(t, "int", fn.In[1].GolangType())
+	assert.Len(t, fn.NonErrorOutputs(), 1)
+	assert.Len(t, fn.ErrorOutputs(), 1)
+	assert.False(t, fn.IsGeneric())
+	assert.Nil(t, f.Func("Launch"))
+
+	g, err := Scan("test/testdata/generics/generics.go")
+	assert.Nil(t, err)
+	fn = g.Func("Map")
+	assert.True(t, fn.IsGeneric())
+	assert.Equal(t, "T", fn.TypeParams[0].Name)
+	assert.Equal(t, "any", fn.TypeParams[0].GolangType())
+	assert.Equal(t, "R", fn.TypeParams[1].Name)
+	assert.Equal(t, "func(T) R", fn.In[1].GolangType())
+}
+
+func TestStruct_Methods(t *testing.T) {
+	pkg, err := ScanPackage("test")
+	assert.Nil(t, err)
+	assert.Len(t, pkg.Funcs, 1)
+
+	fuel := pkg.Struct("Fuel")
+	assert.Len(t, fuel.Methods, 1)
+	assert.False(t, fuel.Method("Empty").HasPointerReceiver())
+	assert.Equal(t, "f", fuel.Method("Empty").Receiver.Name)
+	assert.Equal(t, "Fuel", fuel.Method("Empty").ReceiverType())
+	assert.Len(t, fuel.ValueMethodSet(), 1)
+
+	rocket := pkg.Struct("Rocket")
+	assert.Len(t, rocket.Methods, 1)
+	assert.True(t, rocket.Method("Refill").HasPointerReceiver())
+	assert.Len(t, rocket.ValueMethodSet(), 0)
+	assert.Len(t, rocket.PointerMethodSet(), 1)
+	assert.Nil(t, rocket.Method("Empty"))
+
+	// single file scan sees only methods of the same file
+	f, err := Scan("test/sample.go")
+	assert.Nil(t, err)
+	assert.Len(t, f.Struct("Rocket").Methods, 0)
+	assert.Len(t, f.Struct("Fuel").Methods, 1)
+
+	// lookups in siblings don't change methods of scanned file
+	assert.True(t, f.Interface("Fs").Method("Call").In[2].IsStruct())
+	assert.Len(t, f.Struct("Rocket").Methods, 0)
+	f, err = (&Config{Typed: true}).Scan("test/sample.go")
+	assert.Nil(t, err)
+	assert.Len(t, f.Struct("Rocket").Methods, 0)
+}
+
+func TestStruct_Methods_package(t *testing.T) {
+	// sibling: methods are declared in another file of the package
+	f, err := Scan("test/testdata/methods/holder.go")
+	assert.Nil(t, err)
+	item, err := f.ExtractTypeString("Item")
+	assert.Nil(t, err)
+	assert.Len(t, item.Methods, 2)
+	assert.NotNil(t, item.Method("Title"))
+
+	// imported package
+	f, err = Scan("test/testdata/methods/app/app.go")
+	assert.Nil(t, err)
+	item, err = f.ExtractTypeString("methods.Item")
+	assert.Nil(t, err)
+	assert.Len(t, item.Methods, 2)
+	assert.True(t, item.Method("Rename").HasPointerReceiver())
+}
@@ -0,0 +1,185 @@
+package atool
+
+import (
+	"encoding/json"
+	"go/ast"
+	"go/token"
+	"strings"
+)
+
+// Term is an element of type union in constraint interface (ex: ~int)
+type Term struct {
+	Tilde   bool // underlying type is allowed
+	Type    ast.Expr
+	printer *Printer
+}
+
+func (t *Term) GolangType() string {
+	return t.printer.ToString(t.Type)
+}
+
+func (t *Term) Ref() *TypeRef { return AsTypeRef(t.Type, t.printer) }
+
+func (t *Term) String() string {
+	if t.Tilde {
+		return "~" + t.GolangType()
+	}
+	return t.GolangType()
+}
+
+func (t *Term) MarshalJSON() ([]byte, error) {
+	return json.Marshal(&struct {
+		Tilde      bool `json:",omitempty"`
+		GolangType string
+	}{
+		Tilde:      t.Tilde,
+		GolangType: t.GolangType(),
+	})
+}
+
+// Union is a type set defined by list of terms (ex: ~int | ~string)
+type Union []*Term
+
+func (u Union) String() string {
+	var terms []string
+	for _, t := range u {
+		terms = append(terms, t.String())
+	}
+	return strings.Join(terms, " | ")
+}
+
+// IsConstraint checks that interface could be used only as type constraint: it has type elements, comparable,
+// embedded named non-interface types (ex: type MyInt int) or embedded constraints
+func (in *Interface) IsConstraint() bool {
+	return in.isConstraint(make(map[*Interface]bool))
+}
+
+func (in *Interface) isConstraint(visited map[*Interface]bool) bool {
+	if visited[in] {
+		return false
+	}
+	visited[in] = true
+	if len(in.Unions) > 0 {
+		return true
+	}
+	for _, embedded := range in.Embedded {
+		switch embedded.printer.ToString(typeName(embedded.Type)) {
+		case "comparable":
+			return true
+		case "any":
+			continue
+		}
+		iface, err := in.embeddedInterface(embedded)
+		if err == nil && (iface == nil || iface.isConstraint(visited)) {
+			return true
+		}
+	}
+	return false
+}
+
+// AllUnions returns type elements of interface and all embedded interfaces (recursively). Embedded named types
+// which are not interfaces (ex: type MyInt int) are unions of one term. Type set is an intersection of all unions
+func (in *Interface) AllUnions() ([]Union, error) {
+	var res []Union
+	err := in.collectUnions(&res, make(map[*Interface]bool))
+	return res, err
+}
+
+func (in *Interface) collectUnions(res *[]Union, visited map[*Interface]bool) error {
+	if visited[in] {
+		return nil
+	}
+	visited[in] = true
+	*res = append(*res, in.Unions...)
+	for _, embedded := range in.Embedded {
+		if name := embedded.printer.ToString(typeName(embedded.Type)); name == "any" || name == "comparable" {
+			continue
+		}
+		iface, err := in.embeddedInterface(embedded)
+		if err != nil {
+			return err
+		}
+		if iface == nil {
+			*res = append(*res, Union{{Type: embedded.Type, printer: embedded.printer}})
+			continue
+		}
+		if err := iface.collectUnions(res, visited); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// IsGeneric checks that struct has type parameters
+func (s *Struct) IsGeneric() bool {
+	return len(s.TypeParams) > 0
+}
+
+// IsGeneric checks that interface has type parameters
+func (in *Interface) IsGeneric() bool {
+	return len(in.TypeParams) > 0
+}
+
+func getTypeParams(printer *Printer, list *ast.FieldList) []*Arg {
+	if list == nil {
+		return nil
+	}
+	return getArgs(printer, list.List)
+}
+
+// typeParamsString renders type parameters as declaration (ex: [K comparable, V any])
+func typeParamsString(params []*Arg) string {
+	if len(params) == 0 {
+		return ""
+	}
+	var items []string
+	for _, p := range params {
+		items = append(items, p.Name+" "+p.GolangType())
+	}
+	return "[" + strings.Join(items, ", ") + "]"
+}
+
+// isUnion detects embedded element of interface that is not an interface: ~T, A | B or predeclared type
+func isUnion(tp ast.Expr) bool {
+	switch v := tp.(type) {
+	case *ast.BinaryExpr:
+		return v.Op == token.OR
+	case *ast.UnaryExpr:
+		return v.Op == token.TILDE
+	case *ast.ParenExpr:
+		return isUnion(v.X)
+	case *ast.Ident:
+		return isPredeclared(v.Name)
+	case *ast.ArrayType, *ast.MapType, *ast.ChanType, *ast.FuncType, *ast.StructType, *ast.StarExpr:
+		return true
+	}
+	return false
+}
+
+func getUnion(printer *Printer, tp ast.Expr) Union {
+	switch v := tp.(type) {
+	case *ast.BinaryExpr:
+		if v.Op == token.OR {
+			return append(getUnion(printer, v.X), getUnion(printer, v.Y)...)
+		}
+	case *ast.ParenExpr:
+		return getUnion(printer, v.X)
+	case *ast.UnaryExpr:
+		if v.Op == token.TILDE {
+			return Union{{Tilde: true, Type: v.X, printer: printer}}
+		}
+	}
+	return Union{{Type: tp, printer: printer}}
+}
+
+// isPredeclared checks that name is a predeclared non-interface type
+func isPredeclared(name string) bool {
+	switch name {
+	case "bool", "string", "byte", "rune", "uintptr",
+		"int", "int8", "int16", "int32", "int64",
+		"uint", "uint8", "uint16", "uint32", "uint64",
+		"float32", "float64", "complex64", "complex128":
+		return true
+	}
+	return false
+}
@@ -0,0 +1,60 @@
+package atool
+
+import (
+	"encoding/json"
+	"github.com/alecthomas/assert"
+	"testing"
+)
+
+func TestGenerics(t *testing.T) {
+	f, err := Scan("test/testdata/generics/generics.go")
+	assert.Nil(t, err)
+
+	pair := f.Struct("Pair")
+	assert.True(t, pair.IsGeneric())
+	assert.Len(t, pair.TypeParams, 2)
+	assert.Equal(t, "comparable", pair.TypeParams[0].GolangType())
+	assert.Equal(t, "type Pair[K comparable, V any] struct {\n\tKey   K\n\tValue V\n}", pair.GoLang())
+	assert.Len(t, pair.Methods, 1)
+	assert.Equal(t, "Pair", pair.Method("Get").ReceiverType())
+
+	container := f.Interface("Container")
+	assert.True(t, container.IsGeneric())
+	assert.False(t, container.IsConstraint())
+	assert.Equal(t, "type Container[T Number] interface {\n\tItems() []T\n}", container.GoLang())
+
+	number := f.Interface("Number")
+	assert.True(t, number.IsConstraint())
+	assert.Len(t, number.Embedded, 0)
+	assert.Len(t, number.Unions, 1)
+	assert.Equal(t, "~int | ~int64 | float64", number.Unions[0].String())
+	assert.True(t, number.Unions[0][1].Tilde)
+	assert.False(t, number.Unions[0][2].Tilde)
+	assert.Equal(t, "float64", number.Unions[0][2].GolangType())
+
+	ordered := f.Interface("Ordered")
+	assert.True(t, ordered.IsConstraint())
+	assert.Len(t, ordered.Embedded, 2)
+	methods, err := ordered.AllMethods()
+	assert.Nil(t, err)
+	assert.Len(t, methods, 0)
+
+	unions, err := ordered.AllUnions()
+	assert.Nil(t, err)
+	assert.Len(t, unions, 1)
+	assert.Equal(t, "~int | ~int64 | float64", unions[0].String())
+
+	mine := f.Interface("Mine")
+	assert.True(t, mine.IsConstraint())
+	methods, err = mine.AllMethods()
+	assert.Nil(t, err)
+	assert.Len(t, methods, 0)
+	unions, err = mine.AllUnions()
+	assert.Nil(t, err)
+	assert.Len(t, unions, 1)
+	assert.Equal(t, "MyInt", unions[0].String())
+
+	data, err := json.Marshal(pair)
+	assert.Nil(t, err)
+	assert.Contains(t, string(data), `"TypeParams":[{"Name":"K","GolangType":"comparable","IsError":false,"Position":{"Filename":"test/testdata/generics/generics.go","Line":24,"Column":11,`)
+}
@@ -1,6 +1,6 @@
 module github.com/reddec/astools
 
-go 1.12
+go 1.18
 
 require (
 	github.com/Masterminds/semver v1.4.2
@@ -0,0 +1,132 @@
+package atool
+
+import (
+	"github.com/pkg/errors"
+	"go/ast"
+	"go/token"
+	"sync"
+)
+
+const builtinSource = `package builtin
+
+type error interface {
+	Error() string
+}
+`
+
+var (
+	builtinOnce sync.Once
+	builtinFile *File
+)
+
+// builtin returns file with declarations of predeclared types that have methods
+func builtin() *File {
+	builtinOnce.Do(func() {
+		file, err := defaultConfig.scanSource(token.NewFileSet(), "builtin.go", []byte(builtinSource))
+		if err != nil {
+			panic(err)
+		}
+		builtinFile = file
+	})
+	return builtinFile
+}
+
+func (file *File) ExtractInterfaceString(tp string) (*Interface, error) {
+	if iface := builtin().Interface(tp); iface != nil {
+		return iface, nil
+	}
+	src, name, err := file.lookupType(tp, func(f *File, name string) bool { return f.Interface(name) != nil })
+	if err != nil {
+		return nil, err
+	}
+	return src.Interface(name), nil
+}
+
+// AllMethods returns own methods and methods of all embedded interfaces (recursively).
+// Embedded interfaces are resolved locally and from imported packages the same way as ExtractTypeString.
+func (in *Interface) AllMethods() ([]*Method, error) {
+	var res []*Method
+	seen := make(map[string]bool)
+	err := in.collectMethods(&res, seen, make(map[*Interface]bool))
+	return res, err
+}
+
+func (in *Interface) collectMethods(res *[]*Method, seen map[string]bool, visited map[*Interface]bool) error {
+	if visited[in] {
+		return nil
+	}
+	visited[in] = true
+	for _, m := range in.Methods {
+		if !seen[m.Name] {
+			seen[m.Name] = true
+			*res = append(*res, m)
+		}
+	}
+	for _, embedded := range in.Embedded {
+		name := embedded.printer.ToString(typeName(embedded.Type))
+		if name == "any" || name == "comparable" {
+			continue
+		}
+		iface, err := in.embeddedInterface(embedded)
+		if err != nil {
+			return err
+		}
+		if iface == nil {
+			continue // type term has no methods
+		}
+		if err := iface.collectMethods(res, seen, visited); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// embeddedInterface resolves embedded element of interface. Returns nil for named types which are not
+// interfaces (ex: type MyInt int): such elements are terms of type set.
+// Embedded interface literal (ex: interface{ M() }) is returned as interface with name of the embedding one
+func (in *Interface) embeddedInterface(embedded *Arg) (*Interface, error) {
+	tp := embedded.Type
+	for paren, ok := tp.(*ast.ParenExpr); ok; paren, ok = tp.(*ast.ParenExpr) {
+		tp = paren.X
+	}
+	if literal, ok := tp.(*ast.InterfaceType); ok {
+		iface := &Interface{Name: in.Name, Definition: literal, File: in.File, printer: embedded.printer}
+		iface.addElements(literal)
+		return iface, nil
+	}
+	name := embedded.printer.ToString(typeName(embedded.Type))
+	if in.File == nil {
+		return nil, errors.Errorf("resolve embedded %v of %v: unknown source file", name, in.Name)
+	}
+	named, err := in.File.ExtractNamedString(name)
+	if err != nil {
+		return nil, errors.Wrapf(err, "resolve embedded %v of %v", name, in.Name)
+	}
+	iface, _ := named.(*Interface)
+	return iface, nil
+}
+
+// typeName strips pointer and type arguments from the expression
+func typeName(tp ast.Expr) ast.Expr {
+	if star, ok := tp.(*ast.StarExpr); ok {
+		tp = star.X
+	}
+	switch v := tp.(type) {
+	case *ast.IndexExpr:
+		return v.X
+	case *ast.IndexListExpr:
+		return v.X
+	}
+	return tp
+}
+
+// embeddedName is implicit name of embedded type: type name without pointer, package and type arguments
+func embeddedName(tp ast.Expr) string {
+	switch v := typeName(tp).(type) {
+	case *ast.Ident:
+		return v.Name
+	case *ast.SelectorExpr:
+		return v.Sel.Name
+	}
+	return ""
+}
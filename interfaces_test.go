@@ -0,0 +1,39 @@
+package atool
+
+import (
+	"github.com/alecthomas/assert"
+	"testing"
+)
+
+func TestInterface_AllMethods(t *testing.T) {
+	f, err := Scan("test/testdata/embed/embed.go")
+	assert.Nil(t, err)
+	store := f.Interface("Store")
+	assert.Len(t, store.Methods, 2)
+	assert.Len(t, store.Embedded, 3)
+	assert.Equal(t, "Base", store.Embedded[0].Name)
+	assert.Equal(t, "Closer", store.Embedded[1].Name)
+	assert.Equal(t, "io.Closer", store.Embedded[1].GolangType())
+
+	methods, err := store.AllMethods()
+	assert.Nil(t, err)
+	var names []string
+	for _, m := range methods {
+		names = append(names, m.Name)
+	}
+	assert.Equal(t, []string{"Save", "Close", "ID", "Error"}, names)
+
+	methods, err = f.Interface("Closable").AllMethods()
+	assert.Nil(t, err)
+	names = nil
+	for _, m := range methods {
+		names = append(names, m.Name)
+	}
+	assert.Equal(t, []string{"Flush", "Close"}, names)
+	unions, err := f.Interface("Closable").AllUnions()
+	assert.Nil(t, err)
+	assert.Len(t, unions, 0)
+
+	_, err = f.Interface("Broken").AllMethods()
+	assert.NotNil(t, err)
+}
@@ -0,0 +1,158 @@
+package atool
+
+import (
+	"go/ast"
+	"reflect"
+)
+
+// maxUnderlyingDepth limits chain of named types to avoid loops in invalid sources
+const maxUnderlyingDepth = 32
+
+var predeclaredKinds = map[string]reflect.Kind{
+	"bool":       reflect.Bool,
+	"int":        reflect.Int,
+	"int8":       reflect.Int8,
+	"int16":      reflect.Int16,
+	"int32":      reflect.Int32,
+	"rune":       reflect.Int32,
+	"int64":      reflect.Int64,
+	"uint":       reflect.Uint,
+	"uint8":      reflect.Uint8,
+	"byte":       reflect.Uint8,
+	"uint16":     reflect.Uint16,
+	"uint32":     reflect.Uint32,
+	"uint64":     reflect.Uint64,
+	"uintptr":    reflect.Uintptr,
+	"float32":    reflect.Float32,
+	"float64":    reflect.Float64,
+	"complex64":  reflect.Complex64,
+	"complex128": reflect.Complex128,
+	"string":     reflect.String,
+	"error":      reflect.Interface,
+	"any":        reflect.Interface,
+	"comparable": reflect.Interface,
+}
+
+// Kind returns basic kind of argument type. Named types and aliases (local and imported) are resolved to their
+// underlying types. Returns reflect.Invalid if type can't be resolved (ex: type parameter)
+func (arg *Arg) Kind() reflect.Kind {
+	tp, _ := underlying(arg.Type, arg.printer)
+	return kindOf(tp)
+}
+
+func (arg *Arg) IsChan() bool { return arg.Kind() == reflect.Chan }
+
+func (arg *Arg) IsFunc() bool { return arg.Kind() == reflect.Func }
+
+func (arg *Arg) IsInterface() bool { return arg.Kind() == reflect.Interface }
+
+func (arg *Arg) IsStruct() bool { return arg.Kind() == reflect.Struct }
+
+func (arg *Arg) IsComplex() bool {
+	switch arg.Kind() {
+	case reflect.Complex64, reflect.Complex128:
+		return true
+	}
+	return false
+}
+
+// IsNamed checks that type is a declared (not predeclared) type, including instantiated generics
+func (arg *Arg) IsNamed() bool {
+	switch v := arg.Type.(type) {
+	case *ast.Ident:
+		_, ok := predeclaredKinds[v.Name]
+		return !ok
+	case *ast.SelectorExpr, *ast.IndexExpr, *ast.IndexListExpr:
+		return true
+	}
+	return false
+}
+
+// MapKey returns key of map (including named map types) or nil
+func (arg *Arg) MapKey() *Arg {
+	tp, printer := underlying(arg.Type, arg.printer)
+	v, ok := tp.(*ast.MapType)
+	if !ok {
+		return nil
+	}
+	return &Arg{Type: v.Key, printer: printer}
+}
+
+// MapValue returns value of map (including named map types) or nil
+func (arg *Arg) MapValue() *Arg {
+	tp, printer := underlying(arg.Type, arg.printer)
+	v, ok := tp.(*ast.MapType)
+	if !ok {
+		return nil
+	}
+	return &Arg{Type: v.Value, printer: printer}
+}
+
+// underlying resolves named types and aliases to type expression of declaration and printer of its source.
+// Returns nil expression if type can't be resolved
+func underlying(tp ast.Expr, printer *Printer) (ast.Expr, *Printer) {
+	for i := 0; i < maxUnderlyingDepth; i++ {
+		switch v := tp.(type) {
+		case *ast.ParenExpr:
+			tp = v.X
+			continue
+		case *ast.Ident:
+			if _, ok := predeclaredKinds[v.Name]; ok {
+				return tp, printer
+			}
+		case *ast.SelectorExpr:
+			if printer.ToString(v) == "unsafe.Pointer" {
+				return tp, printer
+			}
+		case *ast.IndexExpr, *ast.IndexListExpr:
+		default:
+			return tp, printer
+		}
+		if printer == nil || printer.source == nil {
+			return nil, nil
+		}
+		named, err := printer.source.ExtractNamedString(printer.ToString(typeName(tp)))
+		if err != nil {
+			return nil, nil
+		}
+		switch v := named.(type) {
+		case *Struct:
+			return v.Definition, v.printer
+		case *Interface:
+			return v.Definition, v.printer
+		case *NamedType:
+			tp, printer = v.Type, v.printer
+		}
+	}
+	return nil, nil
+}
+
+// kindOf returns kind of resolved type expression
+func kindOf(tp ast.Expr) reflect.Kind {
+	switch v := tp.(type) {
+	case *ast.Ident:
+		return predeclaredKinds[v.Name]
+	case *ast.SelectorExpr:
+		return reflect.UnsafePointer
+	case *ast.StarExpr:
+		return reflect.Ptr
+	case *ast.ArrayType:
+		if v.Len == nil {
+			return reflect.Slice
+		}
+		return reflect.Array
+	case *ast.Ellipsis:
+		return reflect.Slice
+	case *ast.MapType:
+		return reflect.Map
+	case *ast.ChanType:
+		return reflect.Chan
+	case *ast.FuncType:
+		return reflect.Func
+	case *ast.StructType:
+		return reflect.Struct
+	case *ast.InterfaceType:
+		return reflect.Interface
+	}
+	return reflect.Invalid
+}
@@ -0,0 +1,101 @@
+package atool
+
+import (
+	"github.com/alecthomas/assert"
+	"reflect"
+	"testing"
+)
+
+func TestArg_Kind(t *testing.T) {
+	f, err := Scan("test/kinds/kinds.go")
+	assert.Nil(t, err)
+	st := f.Struct("Kinds")
+	assert.NotNil(t, st)
+
+	expected := map[string]reflect.Kind{
+		"Count":    reflect.Uint32,
+		"Address":  reflect.Uintptr,
+		"Signal":   reflect.Complex128,
+		"Amount":   reflect.Int64,
+		"Money":    reflect.Int64,
+		"Index":    reflect.Map,
+		"IDs":      reflect.Slice,
+		"Handler":  reflect.Func,
+		"Timeout":  reflect.Int64,
+		"Query":    reflect.Map,
+		"Lock":     reflect.Struct,
+		"Reader":   reflect.Interface,
+		"Err":      reflect.Interface,
+		"Events":   reflect.Chan,
+		"Parent":   reflect.Ptr,
+		"Raw":      reflect.UnsafePointer,
+		"Checksum": reflect.Array,
+	}
+	for name, kind := range expected {
+		assert.Equal(t, kind, st.Field(name).Kind(), name)
+	}
+
+	assert.True(t, st.Field("Count").IsInteger())
+	assert.True(t, st.Field("Address").IsInteger())
+	assert.True(t, st.Field("Money").IsInteger())
+	assert.True(t, st.Field("Timeout").IsSimple())
+	assert.True(t, st.Field("Signal").IsSimple())
+	assert.True(t, st.Field("Signal").IsComplex())
+	assert.False(t, st.Field("Lock").IsSimple())
+	assert.True(t, st.Field("Lock").IsStruct())
+	assert.True(t, st.Field("Reader").IsInterface())
+	assert.True(t, st.Field("Handler").IsFunc())
+	assert.True(t, st.Field("Events").IsChan())
+
+	assert.True(t, st.Field("Amount").IsNamed())
+	assert.True(t, st.Field("Timeout").IsNamed())
+	assert.False(t, st.Field("Count").IsNamed())
+	assert.False(t, st.Field("Parent").IsNamed())
+
+	index := st.Field("Index")
+	assert.True(t, index.IsMap())
+	assert.Equal(t, "string", index.MapKey().GolangType())
+	assert.Equal(t, reflect.Int64, index.MapValue().Kind())
+	query := st.Field("Query")
+	assert.Equal(t, "[]string", query.MapValue().GolangType())
+	assert.Nil(t, st.Field("Count").MapKey())
+
+	ids := st.Field("IDs")
+	assert.True(t, ids.IsArray())
+	assert.Equal(t, reflect.Uint32, ids.ArrayItem().Kind())
+
+	values := f.Func("Sum").In[1]
+	assert.True(t, values.IsArray())
+	assert.Equal(t, "uint32", values.ArrayItem().GolangType())
+	assert.Nil(t, f.Func("Sum").In[0].ArrayItem())
+}
+
+func TestArg_Kind_remembered(t *testing.T) {
+	cfg := &Config{
+		Resolver: MapResolver{"example.com/lib": "test/virtual/lib"},
+		Overlay: map[string][]byte{
+			"test/virtual/lib/lib.go":   []byte("package lib\n\ntype Item int\n"),
+			"test/virtual/app/model.go": []byte("package app\n\ntype Local int\n"),
+			"test/virtual/app/app.go": []byte(`package app
+
+import "example.com/lib"
+
+type App struct {
+	Current lib.Item
+	Own     Local
+}
+`),
+		},
+	}
+	f, err := cfg.Scan("test/virtual/app/app.go")
+	assert.Nil(t, err)
+	st := f.Struct("App")
+	assert.Equal(t, reflect.Int, st.Field("Current").Kind())
+	assert.Equal(t, reflect.Int, st.Field("Own").Kind())
+
+	// sources are not parsed again by repeated calls
+	cfg.Overlay["test/virtual/lib/lib.go"] = []byte("package lib\n\ntype Item string\n")
+	cfg.Overlay["test/virtual/app/model.go"] = []byte("package app\n\ntype Local string\n")
+	assert.Equal(t, reflect.Int, st.Field("Current").Kind())
+	assert.Equal(t, reflect.Int, st.Field("Own").Kind())
+}
@@ -0,0 +1,287 @@
+package atool
+
+import (
+	"bufio"
+	"github.com/pkg/errors"
+	"go/build"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// Module is a parsed go.mod file
+type Module struct {
+	Path    string            // module path
+	Dir     string            // directory of go.mod
+	Require map[string]string // required module path -> version
+	Replace []*Replace
+}
+
+// Replace is a replace directive of go.mod. New is a local directory if NewVersion is empty
+type Replace struct {
+	Old        string
+	OldVersion string `json:",omitempty"` // empty means any version
+	New        string
+	NewVersion string `json:",omitempty"`
+	dir        string // directory of file with directive, base for relative paths
+}
+
+// IsLocal checks that module replaced by the local directory
+func (r *Replace) IsLocal() bool {
+	return r.NewVersion == "" && isLocalPath(r.New)
+}
+
+// FindModule looks for go.mod in the directory and all parents. Returns nil without error if there is no module
+func FindModule(dir string) (*Module, error) {
+	dir, err := filepath.Abs(dir)
+	if err != nil {
+		return nil, err
+	}
+	for {
+		filename := filepath.Join(dir, "go.mod")
+		if st, err := os.Stat(filename); err == nil && !st.IsDir() {
+			return ParseModFile(filename)
+		}
+		up := filepath.Dir(dir)
+		if up == dir {
+			return nil, nil
+		}
+		dir = up
+	}
+}
+
+// ParseModFile reads module path, requirements and replacements from go.mod
+func ParseModFile(filename string) (*Module, error) {
+	dir, err := filepath.Abs(filepath.Dir(filename))
+	if err != nil {
+		return nil, err
+	}
+	mod := &Module{Dir: dir, Require: make(map[string]string)}
+	err = parseDirectives(filename, func(verb string, args []string) error {
+		switch verb {
+		case "module":
+			if len(args) != 1 {
+				return errors.New("usage: module path")
+			}
+			mod.Path = args[0]
+		case "require":
+			if len(args) != 2 {
+				return errors.New("usage: require module/path v1.2.3")
+			}
+			mod.Require[args[0]] = args[1]
+		case "replace":
+			replace, err := parseReplace(args, dir)
+			if err != nil {
+				return err
+			}
+			mod.Replace = append(mod.Replace, replace)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return mod, nil
+}
+
+// ResolveImport returns directory of imported package: inside the module, in local replacement or in the module cache.
+// Returns empty string if package is not found
+func (m *Module) ResolveImport(importPath string) string {
+	if sub, ok := subPath(m.Path, importPath); ok {
+		return existingDir(filepath.Join(m.Dir, sub))
+	}
+	var best, version string
+	for path, ver := range m.Require {
+		if _, ok := subPath(path, importPath); ok && len(path) > len(best) {
+			best, version = path, ver
+		}
+	}
+	for _, r := range m.Replace {
+		// replaced modules are not always required (ex: in workspace)
+		if _, ok := subPath(r.Old, importPath); ok && len(r.Old) > len(best) {
+			best, version = r.Old, r.OldVersion
+		}
+	}
+	if best == "" {
+		return ""
+	}
+	sub, _ := subPath(best, importPath)
+	return resolveRequirement(m.Replace, best, version, sub)
+}
+
+// resolveRequirement finds directory of a package of the required module with respect to replacements.
+// First matched replacement wins
+func resolveRequirement(replaces []*Replace, modPath, version, sub string) string {
+	for _, r := range replaces {
+		if r.Old != modPath || (r.OldVersion != "" && r.OldVersion != version) {
+			continue
+		}
+		if r.IsLocal() {
+			dir := r.New
+			if !filepath.IsAbs(dir) {
+				dir = filepath.Join(r.dir, dir)
+			}
+			return existingDir(filepath.Join(dir, sub))
+		}
+		modPath, version = r.New, r.NewVersion
+		break
+	}
+	if version == "" {
+		return ""
+	}
+	return existingDir(filepath.Join(ModCacheDir(), escapeModPath(modPath)+"@"+escapeModPath(version), sub))
+}
+
+// ModCacheDir returns GOMODCACHE or default location of module cache
+func ModCacheDir() string {
+	if dir := os.Getenv("GOMODCACHE"); dir != "" {
+		return dir
+	}
+	gopath := filepath.SplitList(build.Default.GOPATH)
+	if len(gopath) == 0 {
+		return ""
+	}
+	return filepath.Join(gopath[0], "pkg", "mod")
+}
+
+// escapeModPath encodes upper case letters as !lower (same as module cache does)
+func escapeModPath(path string) string {
+	var buf strings.Builder
+	for _, r := range path {
+		if unicode.IsUpper(r) {
+			buf.WriteByte('!')
+			buf.WriteRune(unicode.ToLower(r))
+		} else {
+			buf.WriteRune(r)
+		}
+	}
+	return buf.String()
+}
+
+// subPath returns relative path of the package inside the module
+func subPath(modPath, importPath string) (string, bool) {
+	if modPath == "" {
+		return "", false
+	}
+	if importPath == modPath {
+		return "", true
+	}
+	if strings.HasPrefix(importPath, modPath+"/") {
+		return importPath[len(modPath)+1:], true
+	}
+	return "", false
+}
+
+func existingDir(dir string) string {
+	if st, err := os.Stat(dir); err != nil || !st.IsDir() {
+		return ""
+	}
+	return dir
+}
+
+func isLocalPath(path string) bool {
+	return filepath.IsAbs(path) || path == "." || path == ".." ||
+		strings.HasPrefix(path, "./") || strings.HasPrefix(path, "../") ||
+		strings.HasPrefix(path, `.\`) || strings.HasPrefix(path, `..\`)
+}
+
+// parseReplace parses arguments of replace directive: old [version] => new [version]
+func parseReplace(args []string, dir string) (*Replace, error) {
+	arrow := -1
+	for i, arg := range args {
+		if arg == "=>" {
+			arrow = i
+			break
+		}
+	}
+	if arrow < 1 || arrow > 2 || len(args)-arrow-1 < 1 || len(args)-arrow-1 > 2 {
+		return nil, errors.New("usage: replace module/path [v1.2.3] => other/module v1.4.5 | ../local/path")
+	}
+	r := &Replace{Old: args[0], New: args[arrow+1], dir: dir}
+	if arrow == 2 {
+		r.OldVersion = args[1]
+	}
+	if len(args) == arrow+3 {
+		r.NewVersion = args[arrow+2]
+	}
+	return r, nil
+}
+
+// parseDirectives reads go.mod-like file (go.mod, go.work) and calls handler for every directive.
+// Blocks (verb ( ... )) are unfolded to separate directives
+func parseDirectives(filename string, handler func(verb string, args []string) error) error {
+	f, err := os.Open(filename)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	scanner := bufio.NewScanner(f)
+	var block string
+	var line int
+	for scanner.Scan() {
+		line++
+		tokens, err := splitDirective(scanner.Text())
+		if err != nil {
+			return errors.Wrapf(err, "%v:%v", filename, line)
+		}
+		if len(tokens) == 0 {
+			continue
+		}
+		if block != "" {
+			if len(tokens) == 1 && tokens[0] == ")" {
+				block = ""
+				continue
+			}
+			tokens = append([]string{block}, tokens...)
+		} else if len(tokens) == 2 && tokens[1] == "(" {
+			block = tokens[0]
+			continue
+		}
+		if err := handler(tokens[0], tokens[1:]); err != nil {
+			return errors.Wrapf(err, "%v:%v", filename, line)
+		}
+	}
+	return scanner.Err()
+}
+
+// splitDirective splits line by spaces without comments. Quoted strings are unquoted
+func splitDirective(line string) ([]string, error) {
+	var res []string
+	for {
+		line = strings.TrimLeft(line, " \t\r")
+		if line == "" || strings.HasPrefix(line, "//") {
+			return res, nil
+		}
+		switch line[0] {
+		case '"', '`':
+			end := 1
+			for end < len(line) && line[end] != line[0] {
+				if line[0] == '"' && line[end] == '\\' {
+					end++
+				}
+				end++
+			}
+			if end >= len(line) {
+				return nil, errors.New("unterminated string")
+			}
+			value, err := strconv.Unquote(line[:end+1])
+			if err != nil {
+				return nil, err
+			}
+			res = append(res, value)
+			line = line[end+1:]
+		default:
+			end := strings.IndexAny(line, " \t\r")
+			if comment := strings.Index(line, "//"); comment >= 0 && (end < 0 || comment < end) {
+				end = comment
+			}
+			if end < 0 {
+				end = len(line)
+			}
+			res = append(res, line[:end])
+			line = line[end:]
+		}
+	}
+}
@@ -0,0 +1,42 @@
+package atool
+
+import (
+	"github.com/alecthomas/assert"
+	"path/filepath"
+	"testing"
+)
+
+func TestParseModFile(t *testing.T) {
+	mod, err := FindModule("test/testdata/modules/app/internal/model")
+	assert.Nil(t, err)
+	root, _ := filepath.Abs("test/testdata/modules")
+	assert.Equal(t, "example.com/app", mod.Path)
+	assert.Equal(t, filepath.Join(root, "app"), mod.Dir)
+	assert.Equal(t, "v1.4.2", mod.Require["github.com/Masterminds/semver"])
+	assert.Equal(t, "v0.0.0-20180709203117-cd690d0c9e24", mod.Require["github.com/shopspring/decimal"])
+	assert.Len(t, mod.Replace, 2)
+	assert.True(t, mod.Replace[0].IsLocal())
+	assert.Equal(t, &Replace{Old: "github.com/pkg/errors", OldVersion: "v0.9.0", New: "github.com/pkg/errors", NewVersion: "v0.8.1", dir: mod.Dir}, mod.Replace[1])
+
+	assert.Equal(t, filepath.Join(root, "app/internal/model"), mod.ResolveImport("example.com/app/internal/model"))
+	assert.Equal(t, filepath.Join(root, "lib"), mod.ResolveImport("example.com/lib"))
+	assert.Equal(t, filepath.Join(ModCacheDir(), "github.com/!masterminds/semver@v1.4.2"), mod.ResolveImport("github.com/Masterminds/semver"))
+	assert.Equal(t, filepath.Join(ModCacheDir(), "github.com/pkg/errors@v0.8.1"), mod.ResolveImport("github.com/pkg/errors"))
+	assert.Equal(t, "", mod.ResolveImport("example.com/app/unknown"))
+	assert.Equal(t, "", mod.ResolveImport("example.com/other"))
+
+	mod, err = FindModule("/")
+	assert.Nil(t, err)
+	assert.Nil(t, mod)
+}
+
+func TestFile_ExtractType_modules(t *testing.T) {
+	f, err := Scan("test/testdata/modules/app/main.go")
+	assert.Nil(t, err)
+	app := f.Struct("App")
+	for _, field := range app.Fields {
+		st, err := f.ExtractType(field.Type)
+		assert.Nil(t, err, field.Name)
+		assert.Equal(t, field.GolangType(), st.File.Package+"."+st.Name)
+	}
+}
@@ -0,0 +1,137 @@
+package atool
+
+import (
+	"bytes"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+)
+
+// ScanSource parses file from memory. Name is used as file location: siblings and imports are looked up relative to it
+func ScanSource(name string, content []byte) (*File, error) {
+	return defaultConfig.ScanSource(name, content)
+}
+
+// ScanSource parses file from memory as if it was saved by name (content overlays file on disk)
+func (cfg *Config) ScanSource(name string, content []byte) (*File, error) {
+	cp := *cfg
+	cp.Overlay = map[string][]byte{name: content}
+	for path, data := range cfg.Overlay {
+		if !samePath(path, name) {
+			cp.Overlay[path] = data
+		}
+	}
+	return cp.Scan(name)
+}
+
+// overlay returns content of file from overlay
+func (cfg *Config) overlay(filename string) ([]byte, bool) {
+	for path, content := range cfg.Overlay {
+		if samePath(path, filename) {
+			return content, true
+		}
+	}
+	return nil, false
+}
+
+// readFile reads file from overlay or from disk
+func (cfg *Config) readFile(filename string) ([]byte, error) {
+	if content, ok := cfg.overlay(filename); ok {
+		return content, nil
+	}
+	return ioutil.ReadFile(filename)
+}
+
+func (cfg *Config) openFile(filename string) (io.ReadCloser, error) {
+	if content, ok := cfg.overlay(filename); ok {
+		return ioutil.NopCloser(bytes.NewReader(content)), nil
+	}
+	return os.Open(filename)
+}
+
+// readDir lists directory on disk with files of overlay. Directory could exist only in overlay
+func (cfg *Config) readDir(dir string) ([]os.FileInfo, error) {
+	var overlaid []os.FileInfo
+	for path, content := range cfg.Overlay {
+		if samePath(filepath.Dir(path), dir) {
+			overlaid = append(overlaid, &overlayInfo{name: filepath.Base(path), size: int64(len(content))})
+		}
+	}
+	content, err := ioutil.ReadDir(dir)
+	if err != nil && (len(overlaid) == 0 || !os.IsNotExist(err)) {
+		return nil, err
+	}
+	var res []os.FileInfo
+	for _, info := range content {
+		if !hasFile(overlaid, info.Name()) {
+			res = append(res, info)
+		}
+	}
+	res = append(res, overlaid...)
+	sort.Slice(res, func(i, j int) bool { return res[i].Name() < res[j].Name() })
+	return res, nil
+}
+
+// overlayResolver finds packages which exist only in overlay: directories of overlay files inside the module of
+// importing directory
+type overlayResolver struct {
+	cfg *Config
+}
+
+func (r overlayResolver) ResolveImport(fromDir, importPath string) (string, error) {
+	mod, err := FindModule(fromDir)
+	if err != nil || mod == nil {
+		return "", err
+	}
+	sub, ok := subPath(mod.Path, importPath)
+	if !ok {
+		return "", nil
+	}
+	dir := filepath.Join(mod.Dir, sub)
+	for path := range r.cfg.Overlay {
+		if samePath(filepath.Dir(path), dir) {
+			return dir, nil
+		}
+	}
+	return "", nil
+}
+
+func hasFile(list []os.FileInfo, name string) bool {
+	for _, info := range list {
+		if info.Name() == name {
+			return true
+		}
+	}
+	return false
+}
+
+func samePath(a, b string) bool {
+	if a == b {
+		return true
+	}
+	absA, err := filepath.Abs(a)
+	if err != nil {
+		return false
+	}
+	absB, err := filepath.Abs(b)
+	if err != nil {
+		return false
+	}
+	return absA == absB
+}
+
+// overlayInfo describes file of overlay
+type overlayInfo struct {
+	name string
+	size int64
+}
+
+func (fi *overlayInfo) Name() string       { return fi.name }
+func (fi *overlayInfo) Size() int64        { return fi.size }
+func (fi *overlayInfo) Mode() os.FileMode  { return 0644 }
+func (fi *overlayInfo) ModTime() time.Time { return time.Time{} }
+func (fi *overlayInfo) IsDir() bool        { return false }
+func (fi *overlayInfo) Sys() interface{}   { return nil }
@@ -0,0 +1,76 @@
+package atool
+
+import (
+	"github.com/alecthomas/assert"
+	"testing"
+)
+
+func TestScanSource(t *testing.T) {
+	f, err := ScanSource("test/sample.go", []byte(`package sample
+
+type Rocket struct {
+	Data SampleData
+	Crew int
+}
+`))
+	assert.Nil(t, err)
+	assert.NotNil(t, f.Struct("Rocket").Field("Crew"))
+	assert.Nil(t, f.Struct("Rocket").Field("Power"))
+	// siblings are read from disk
+	data, err := f.ExtractTypeString("SampleData")
+	assert.Nil(t, err)
+	assert.Equal(t, "test/ext.go", data.File.Location())
+}
+
+func TestConfig_Overlay(t *testing.T) {
+	cfg := &Config{
+		Resolver: MapResolver{"example.com/lib": "test/virtual/lib"},
+		Overlay: map[string][]byte{
+			"test/values/extra.go":    []byte("package values\n\ntype Extra struct{ X int }\n"),
+			"test/virtual/lib/lib.go": []byte("package lib\n\ntype Item struct{ Name string }\n"),
+		},
+	}
+	pkg, err := cfg.ScanPackage("test/values")
+	assert.Nil(t, err)
+	assert.NotNil(t, pkg.Struct("Extra"))
+
+	pkg, err = cfg.ScanPackage("test/virtual/lib")
+	assert.Nil(t, err)
+	assert.NotNil(t, pkg.Struct("Item"))
+
+	f, err := cfg.ScanSource("test/virtual/app/app.go", []byte(`package app
+
+import "example.com/lib"
+
+type App struct {
+	Current lib.Item
+}
+`))
+	assert.Nil(t, err)
+	item, err := f.ExtractTypeString("lib.Item")
+	assert.Nil(t, err)
+	assert.NotNil(t, item.Field("Name"))
+	assert.Equal(t, "example.com/lib", item.File.Import)
+}
+
+func TestConfig_Overlay_module(t *testing.T) {
+	// package exists only in overlay and resolved by module of importing file
+	cfg := &Config{
+		Overlay: map[string][]byte{
+			"test/virtual/lib/lib.go": []byte("package lib\n\ntype Item struct{ Name string }\n"),
+		},
+	}
+	f, err := cfg.ScanSource("test/virtual/app/app.go", []byte(`package app
+
+import "github.com/reddec/astools/test/virtual/lib"
+
+type App struct {
+	Current lib.Item
+}
+`))
+	assert.Nil(t, err)
+	item, err := f.ExtractTypeString("lib.Item")
+	assert.Nil(t, err)
+	assert.NotNil(t, item.Field("Name"))
+	assert.Equal(t, "github.com/reddec/astools/test/virtual/lib", item.File.Import)
+}
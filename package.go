@@ -0,0 +1,144 @@
+package atool
+
+import (
+	"github.com/pkg/errors"
+	"go/token"
+	"go/types"
+)
+
+// Package is a merged view of all non-test files of one directory
+type Package struct {
+	Name       string
+	Import     string // optional field
+	Comment    string
+	Imports    map[string]string
+	Values     []*Value
+	Interfaces []*Interface   `json:",omitempty"`
+	Structs    []*Struct      `json:",omitempty"`
+	Funcs      []*Func        `json:",omitempty"`
+	Types      []*NamedType   `json:",omitempty"`
+	Enums      []*Enum        `json:",omitempty"`
+	Files      []*File        `json:"-"`
+	Tokens     *token.FileSet `json:"-"`
+	location   string
+	// problems of all files, defined only in partial mode
+	Diagnostics []*Diagnostic `json:",omitempty"`
+	// defined only in typed mode
+	TypesPackage *types.Package `json:"-"`
+	TypesInfo    *types.Info    `json:"-"`
+}
+
+func (p *Package) Location() string { return p.location }
+
+func (p *Package) Value(name string) *Value {
+	for _, v := range p.Values {
+		if v.Name == name {
+			return v
+		}
+	}
+	return nil
+}
+
+func (p *Package) Interface(name string) *Interface {
+	for _, v := range p.Interfaces {
+		if v.Name == name {
+			return v
+		}
+	}
+	return nil
+}
+
+func (p *Package) Struct(name string) *Struct {
+	for _, v := range p.Structs {
+		if v.Name == name {
+			return v
+		}
+	}
+	return nil
+}
+
+func (p *Package) Type(name string) *NamedType {
+	for _, v := range p.Types {
+		if v.Name == name {
+			return v
+		}
+	}
+	return nil
+}
+
+func (p *Package) Enum(name string) *Enum {
+	for _, v := range p.Enums {
+		if v.Name == name {
+			return v
+		}
+	}
+	return nil
+}
+
+func (p *Package) Func(name string) *Func {
+	for _, v := range p.Funcs {
+		if v.Name == name {
+			return v
+		}
+	}
+	return nil
+}
+
+// ScanPackage parses all non-test .go files of the directory with one shared file set
+func ScanPackage(dir string) (*Package, error) {
+	return defaultConfig.ScanPackage(dir)
+}
+
+func (cfg *Config) ScanPackage(dir string) (*Package, error) {
+	pkg := &Package{
+		Tokens:   token.NewFileSet(),
+		Imports:  make(map[string]string),
+		location: dir,
+	}
+	files, err := cfg.scanDir(pkg.Tokens, dir)
+	if err != nil {
+		return nil, err
+	}
+	for _, file := range files {
+		if pkg.Name == "" {
+			pkg.Name = file.Package
+		} else if pkg.Name != file.Package {
+			return nil, errors.Errorf("multiple packages %v and %v in %v", pkg.Name, file.Package, dir)
+		}
+		pkg.Files = append(pkg.Files, file)
+	}
+	if len(pkg.Files) == 0 {
+		return nil, errors.Errorf("no go files in %v", dir)
+	}
+	var methods []*Method
+	var consts []*constEntry
+	for _, file := range pkg.Files {
+		file.near = pkg.Files
+		methods = append(methods, file.methods...)
+		consts = append(consts, file.consts...)
+		if pkg.Comment == "" {
+			pkg.Comment = file.Comment
+		}
+		for path, alias := range file.Imports {
+			pkg.Imports[path] = alias
+		}
+		pkg.Values = append(pkg.Values, file.Values...)
+		pkg.Interfaces = append(pkg.Interfaces, file.Interfaces...)
+		pkg.Structs = append(pkg.Structs, file.Structs...)
+		pkg.Funcs = append(pkg.Funcs, file.Funcs...)
+		pkg.Types = append(pkg.Types, file.Types...)
+	}
+	attachMethods(pkg.Structs, pkg.Types, methods)
+	pkg.Enums = getEnums(consts, pkg.Type)
+	evaluateValues(pkg.Values, consts)
+	if cfg.Typed {
+		pkg.TypesPackage, pkg.TypesInfo, err = cfg.check(pkg.Tokens, pkg.Files)
+		if err != nil {
+			return nil, errors.Wrapf(err, "check %v", dir)
+		}
+	}
+	for _, file := range pkg.Files {
+		pkg.Diagnostics = append(pkg.Diagnostics, file.Diagnostics...)
+	}
+	return pkg, nil
+}
@@ -0,0 +1,27 @@
+package atool
+
+import (
+	"github.com/alecthomas/assert"
+	"testing"
+)
+
+func TestScanPackage(t *testing.T) {
+	pkg, err := ScanPackage("test")
+	assert.Nil(t, err)
+	assert.Equal(t, "sample", pkg.Name)
+	assert.Len(t, pkg.Files, 2)
+	var names []string
+	for _, s := range pkg.Structs {
+		names = append(names, s.Name)
+	}
+	assert.EqualValues(t, []string{"SampleData", "Fuel", "Rocket"}, names)
+	assert.Equal(t, "type SampleData struct {\n\tX int\n}", pkg.Struct("SampleData").GoLang())
+	assert.Equal(t, "type Fuel struct {\n\tType   string `json:\"Type\"`\n\tAmount float32 //AAAAAAAAA\n}", pkg.Struct("Fuel").GoLang())
+	assert.NotNil(t, pkg.Interface("Fs"))
+	assert.NotNil(t, pkg.Value("Greeting"))
+	assert.Contains(t, pkg.Comment, "Some package description")
+
+	ex, err := pkg.Files[1].ExtractTypeString("SampleData")
+	assert.Nil(t, err)
+	assert.Equal(t, pkg.Struct("SampleData"), ex)
+}
@@ -0,0 +1,312 @@
+package atool
+
+import (
+	"context"
+	"github.com/pkg/errors"
+	"os"
+	"path/filepath"
+	"runtime"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// Packages is a list of scanned packages ordered by directory
+type Packages []*Package
+
+// ScanPatterns scans packages matched by patterns relative to base directory. See Config.ScanPatterns
+func ScanPatterns(ctx context.Context, baseDir string, patterns ...string) (Packages, error) {
+	return defaultConfig.ScanPatterns(ctx, baseDir, patterns...)
+}
+
+// ScanPatterns scans packages matched by patterns (see MatchPackages) concurrently by Workers.
+// Scan stops on the first error or when context is canceled. Packages are ordered by directory
+func (cfg *Config) ScanPatterns(ctx context.Context, baseDir string, patterns ...string) (Packages, error) {
+	dirs, err := cfg.MatchPackages(baseDir, patterns...)
+	if err != nil {
+		return nil, err
+	}
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	res := make(Packages, len(dirs))
+	failures := make([]error, len(dirs))
+	jobs := make(chan int)
+	var wg sync.WaitGroup
+	for i := 0; i < cfg.workers(); i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for idx := range jobs {
+				if ctx.Err() != nil {
+					continue
+				}
+				pkg, err := cfg.ScanPackage(dirs[idx])
+				if err != nil {
+					failures[idx] = errors.Wrapf(err, "scan %v", dirs[idx])
+					cancel()
+					continue
+				}
+				pkg.setImport(importPath(dirs[idx], ""))
+				res[idx] = pkg
+			}
+		}()
+	}
+feed:
+	for idx := range dirs {
+		select {
+		case jobs <- idx:
+		case <-ctx.Done():
+			break feed
+		}
+	}
+	close(jobs)
+	wg.Wait()
+	for _, err := range failures {
+		if err != nil {
+			return nil, err
+		}
+	}
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	return res, nil
+}
+
+func (cfg *Config) workers() int {
+	if cfg.Workers > 0 {
+		return cfg.Workers
+	}
+	return runtime.NumCPU()
+}
+
+// MatchPackages returns sorted absolute directories of packages matched by patterns relative to base directory.
+// Pattern is a directory, an import path or a path relative to go.work (see ResolvePackage).
+// Pattern with suffix /... matches the package and all packages in subdirectories, except testdata, vendor,
+// directories started with . or _ and nested modules. Directories without files selected by build context are skipped
+func (cfg *Config) MatchPackages(baseDir string, patterns ...string) ([]string, error) {
+	var res []string
+	seen := make(map[string]bool)
+	add := func(dir string) {
+		if abs, err := filepath.Abs(dir); err == nil {
+			dir = abs
+		}
+		if !seen[dir] {
+			seen[dir] = true
+			res = append(res, dir)
+		}
+	}
+	for _, pattern := range patterns {
+		root, recursive := strings.TrimSuffix(pattern, "..."), strings.HasSuffix(pattern, "...")
+		if recursive {
+			if root != "" && !strings.HasSuffix(root, "/") {
+				return nil, errors.Errorf("pattern %v: ... is supported only as a last path element", pattern)
+			}
+			root = strings.TrimSuffix(root, "/")
+			if root == "" {
+				root = "."
+			}
+		}
+		dir, err := patternDir(baseDir, root)
+		if err != nil {
+			return nil, errors.Wrapf(err, "pattern %v", pattern)
+		}
+		if !recursive {
+			if st, err := os.Stat(dir); err == nil && !st.IsDir() {
+				return nil, errors.Errorf("pattern %v: file is not a package", pattern)
+			}
+			add(dir)
+			continue
+		}
+		err = filepath.Walk(dir, func(path string, info os.FileInfo, err error) error {
+			if err != nil {
+				return err
+			}
+			if !info.IsDir() {
+				return nil
+			}
+			if path != dir {
+				name := info.Name()
+				if name == "testdata" || name == "vendor" || strings.HasPrefix(name, ".") || strings.HasPrefix(name, "_") {
+					return filepath.SkipDir
+				}
+				if fileExists(filepath.Join(path, "go.mod")) {
+					return filepath.SkipDir
+				}
+			}
+			if ok, err := cfg.hasPackage(path); err != nil {
+				return err
+			} else if ok {
+				add(path)
+			}
+			return nil
+		})
+		if err != nil {
+			return nil, errors.Wrapf(err, "pattern %v", pattern)
+		}
+	}
+	sort.Strings(res)
+	return res, nil
+}
+
+// patternDir returns directory of pattern without /...
+func patternDir(baseDir, pattern string) (string, error) {
+	if isLocalPath(pattern) {
+		if filepath.IsAbs(pattern) {
+			return pattern, nil
+		}
+		return filepath.Join(baseDir, pattern), nil
+	}
+	return ResolvePackage(baseDir, pattern)
+}
+
+// hasPackage checks that directory has non-test .go files selected by build context
+func (cfg *Config) hasPackage(dir string) (bool, error) {
+	content, err := cfg.readDir(dir)
+	if err != nil {
+		return false, err
+	}
+	ctx := cfg.build()
+	for _, info := range content {
+		name := info.Name()
+		if info.IsDir() || !strings.HasSuffix(name, ".go") || strings.HasSuffix(name, "_test.go") {
+			continue
+		}
+		if ok, err := ctx.MatchFile(dir, name); err == nil && ok {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// setImport sets import path of package and its files if it's not defined
+func (p *Package) setImport(path string) {
+	if p.Import != "" || path == "" {
+		return
+	}
+	p.Import = path
+	for _, file := range p.Files {
+		if file.Import == "" {
+			file.Import = path
+		}
+	}
+}
+
+// Package returns package by import path or, if there is no such path, the first package with the name
+func (ps Packages) Package(name string) *Package {
+	for _, pkg := range ps {
+		if pkg.Import == name {
+			return pkg
+		}
+	}
+	for _, pkg := range ps {
+		if pkg.Name == name {
+			return pkg
+		}
+	}
+	return nil
+}
+
+// lookup returns packages where declaration should be found: the package of qualified name (pkg.Name) or all packages
+func (ps Packages) lookup(name string) (Packages, string) {
+	if dot := strings.LastIndex(name, "."); dot >= 0 {
+		if pkg := ps.Package(name[:dot]); pkg != nil {
+			return Packages{pkg}, name[dot+1:]
+		}
+		return nil, name
+	}
+	return ps, name
+}
+
+// Struct returns the first struct by name. Name could be qualified by package name or import path (ex: model.User)
+func (ps Packages) Struct(name string) *Struct {
+	list, name := ps.lookup(name)
+	for _, pkg := range list {
+		if v := pkg.Struct(name); v != nil {
+			return v
+		}
+	}
+	return nil
+}
+
+// Interface returns the first interface by name, optionally qualified
+func (ps Packages) Interface(name string) *Interface {
+	list, name := ps.lookup(name)
+	for _, pkg := range list {
+		if v := pkg.Interface(name); v != nil {
+			return v
+		}
+	}
+	return nil
+}
+
+// Value returns the first value by name, optionally qualified
+func (ps Packages) Value(name string) *Value {
+	list, name := ps.lookup(name)
+	for _, pkg := range list {
+		if v := pkg.Value(name); v != nil {
+			return v
+		}
+	}
+	return nil
+}
+
+// Func returns the first function by name, optionally qualified
+func (ps Packages) Func(name string) *Func {
+	list, name := ps.lookup(name)
+	for _, pkg := range list {
+		if v := pkg.Func(name); v != nil {
+			return v
+		}
+	}
+	return nil
+}
+
+// Type returns the first named type by name, optionally qualified
+func (ps Packages) Type(name string) *NamedType {
+	list, name := ps.lookup(name)
+	for _, pkg := range list {
+		if v := pkg.Type(name); v != nil {
+			return v
+		}
+	}
+	return nil
+}
+
+// Enum returns the first enum by name, optionally qualified
+func (ps Packages) Enum(name string) *Enum {
+	list, name := ps.lookup(name)
+	for _, pkg := range list {
+		if v := pkg.Enum(name); v != nil {
+			return v
+		}
+	}
+	return nil
+}
+
+// Files returns files of all packages
+func (ps Packages) Files() []*File {
+	var res []*File
+	for _, pkg := range ps {
+		res = append(res, pkg.Files...)
+	}
+	return res
+}
+
+// Diagnostics returns problems of all packages, defined only in partial mode
+func (ps Packages) Diagnostics() []*Diagnostic {
+	var res []*Diagnostic
+	for _, pkg := range ps {
+		res = append(res, pkg.Diagnostics...)
+	}
+	return res
+}
+
+// WithAnnotation returns copies of packages with declarations marked by the annotation key
+func (ps Packages) WithAnnotation(key string) Packages {
+	res := make(Packages, 0, len(ps))
+	for _, pkg := range ps {
+		res = append(res, pkg.WithAnnotation(key))
+	}
+	return res
+}
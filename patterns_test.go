@@ -0,0 +1,53 @@
+package atool
+
+import (
+	"context"
+	"github.com/alecthomas/assert"
+	"path/filepath"
+	"testing"
+)
+
+func TestConfig_MatchPackages(t *testing.T) {
+	cfg := &Config{}
+	dirs, err := cfg.MatchPackages("test/testdata/patterns", "./...")
+	assert.Nil(t, err)
+	root, _ := filepath.Abs("test/testdata/patterns")
+	assert.Equal(t, []string{
+		filepath.Join(root, "api"),
+		filepath.Join(root, "api", "v1"),
+		filepath.Join(root, "model"),
+	}, dirs)
+
+	dirs, err = cfg.MatchPackages(".", "github.com/reddec/astools/test/testdata/patterns/api/...", "./test/testdata/patterns/api")
+	assert.Nil(t, err)
+	assert.Len(t, dirs, 2)
+
+	_, err = cfg.MatchPackages(".", "test/sample.go")
+	assert.NotNil(t, err)
+}
+
+func TestScanPatterns(t *testing.T) {
+	cfg := &Config{Workers: 2}
+	pkgs, err := cfg.ScanPatterns(context.Background(), "test/testdata/patterns", "./...")
+	assert.Nil(t, err)
+	assert.Len(t, pkgs, 3)
+	assert.Equal(t, "api", pkgs[0].Name)
+	assert.Equal(t, "v1", pkgs[1].Name)
+	assert.Equal(t, "model", pkgs[2].Name)
+	assert.Equal(t, "github.com/reddec/astools/test/testdata/patterns/model", pkgs[2].Import)
+
+	assert.NotNil(t, pkgs.Struct("Version"))
+	assert.NotNil(t, pkgs.Struct("model.User"))
+	assert.Nil(t, pkgs.Struct("api.User"))
+	assert.NotNil(t, pkgs.Interface("github.com/reddec/astools/test/testdata/patterns/api.Service"))
+	assert.Len(t, pkgs.Files(), 3)
+
+	user, err := pkgs.Struct("Request").File.ExtractTypeString("model.User")
+	assert.Nil(t, err)
+	assert.True(t, user.File.Import == pkgs[2].Import)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, err = cfg.ScanPatterns(ctx, "test/testdata/patterns", "./...")
+	assert.Equal(t, context.Canceled, err)
+}
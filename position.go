@@ -0,0 +1,39 @@
+package atool
+
+import (
+	"fmt"
+	"go/ast"
+)
+
+// Position is a location of declaration in source. Line and column point to the name of declaration,
+// offsets cover whole declaration
+type Position struct {
+	Filename string
+	Line     int
+	Column   int
+	Offset   int // byte offset of start of declaration
+	End      int // byte offset of end of declaration
+}
+
+func (p *Position) String() string {
+	return fmt.Sprintf("%v:%v:%v", p.Filename, p.Line, p.Column)
+}
+
+// position of declaration by its name and node. Name could be nil (ex: embedded field). Returns nil if source is unknown
+func (p *Printer) position(name *ast.Ident, node ast.Node) *Position {
+	if p == nil || p.file == nil || node == nil {
+		return nil
+	}
+	start := node.Pos()
+	if name != nil {
+		start = name.Pos()
+	}
+	pos := p.file.Position(start)
+	return &Position{
+		Filename: pos.Filename,
+		Line:     pos.Line,
+		Column:   pos.Column,
+		Offset:   p.offset(node.Pos()),
+		End:      p.offset(node.End()),
+	}
+}
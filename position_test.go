@@ -0,0 +1,30 @@
+package atool
+
+import (
+	"github.com/alecthomas/assert"
+	"testing"
+)
+
+func TestPosition(t *testing.T) {
+	f, err := Scan("test/sample.go")
+	assert.Nil(t, err)
+
+	rocket := f.Struct("Rocket")
+	assert.Equal(t, "test/sample.go:22:6", rocket.Position.String())
+	assert.Equal(t, "type Rocket struct", f.Printer.Src[rocket.Position.Offset-5:rocket.Position.Offset+13])
+	assert.Equal(t, byte('}'), f.Printer.Src[rocket.Position.End-1])
+
+	power := rocket.Field("Power")
+	assert.Equal(t, 23, power.Position.Line)
+	assert.Equal(t, 2, power.Position.Column)
+	assert.Equal(t, "Power int", f.Printer.Src[power.Position.Offset:power.Position.End])
+
+	launch := f.Interface("Control").Method("Launch")
+	assert.Equal(t, 43, launch.Position.Line)
+	assert.Equal(t, 37, f.Interface("Control").Position.Line)
+	assert.Equal(t, 51, f.Func("NewRocket").Position.Line)
+	assert.Equal(t, 6, f.Func("NewRocket").Position.Column)
+	assert.Equal(t, 16, f.Func("NewRocket").In[0].Position.Column)
+	assert.Equal(t, 56, f.Struct("Fuel").Method("Empty").Position.Line)
+	assert.Equal(t, "test/sample.go:11:7", f.Value("Greeting").Position.String())
+}
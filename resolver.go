@@ -0,0 +1,122 @@
+package atool
+
+import (
+	"go/build"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// Resolver finds directory with sources of imported package
+type Resolver interface {
+	// ResolveImport returns directory of package imported from the source directory or empty string if not found
+	ResolveImport(fromDir, importPath string) (string, error)
+}
+
+// ResolverFunc is a function adapter for the Resolver
+type ResolverFunc func(fromDir, importPath string) (string, error)
+
+func (f ResolverFunc) ResolveImport(fromDir, importPath string) (string, error) {
+	return f(fromDir, importPath)
+}
+
+// ChainResolver tries resolvers one by one till first found package
+type ChainResolver []Resolver
+
+func (chain ChainResolver) ResolveImport(fromDir, importPath string) (string, error) {
+	for _, r := range chain {
+		dir, err := r.ResolveImport(fromDir, importPath)
+		if err != nil || dir != "" {
+			return dir, err
+		}
+	}
+	return "", nil
+}
+
+// DefaultResolver looks for packages in vendor directory, in workspace or module, in GOPATH and in GOROOT
+func DefaultResolver() Resolver {
+	return ChainResolver{VendorResolver{}, ModuleResolver{}, GopathResolver{}, GorootResolver{}}
+}
+
+// VendorResolver looks for package in the nearest vendor directory
+type VendorResolver struct{}
+
+func (VendorResolver) ResolveImport(fromDir, importPath string) (string, error) {
+	vendorDir, err := findVendorDir(fromDir)
+	if err != nil || vendorDir == "" {
+		return "", err
+	}
+	return existingDir(filepath.Join(vendorDir, importPath)), nil
+}
+
+func findVendorDir(dir string) (string, error) {
+	dir, err := filepath.Abs(dir)
+	if err != nil {
+		return "", err
+	}
+	for {
+		vendorDir := filepath.Join(dir, "vendor")
+		st, err := os.Stat(vendorDir)
+		if err == nil && st.IsDir() {
+			return vendorDir, nil
+		} else if err != nil && !os.IsNotExist(err) {
+			return "", nil
+		}
+		up := filepath.Dir(dir)
+		if up == dir {
+			return "", nil
+		}
+		dir = up
+	}
+}
+
+// ModuleResolver looks for package in go.work workspace or (if there is no workspace) in the module of source directory
+type ModuleResolver struct{}
+
+func (ModuleResolver) ResolveImport(fromDir, importPath string) (string, error) {
+	return resolveModuleImport(fromDir, importPath)
+}
+
+// GopathResolver looks for package in src of every GOPATH entry
+type GopathResolver struct{}
+
+func (GopathResolver) ResolveImport(fromDir, importPath string) (string, error) {
+	for _, root := range filepath.SplitList(build.Default.GOPATH) {
+		if dir := existingDir(filepath.Join(root, "src", importPath)); dir != "" {
+			return dir, nil
+		}
+	}
+	return "", nil
+}
+
+// GorootResolver looks for package of standard library (including vendored by standard library)
+type GorootResolver struct{}
+
+func (GorootResolver) ResolveImport(fromDir, importPath string) (string, error) {
+	src := filepath.Join(build.Default.GOROOT, "src")
+	if dir := existingDir(filepath.Join(src, importPath)); dir != "" {
+		return dir, nil
+	}
+	return existingDir(filepath.Join(src, "vendor", importPath)), nil
+}
+
+// DirResolver maps packages with the import prefix to the local directory (ex: generated code or mirror)
+type DirResolver struct {
+	Prefix string // import path prefix (ex: github.com/example/api)
+	Dir    string // directory of package with the prefix
+}
+
+func (r DirResolver) ResolveImport(fromDir, importPath string) (string, error) {
+	sub, ok := subPath(strings.TrimSuffix(r.Prefix, "/"), importPath)
+	if !ok {
+		return "", nil
+	}
+	return existingDir(filepath.Join(r.Dir, sub)), nil
+}
+
+// MapResolver maps exact import path to the directory (ex: test fixtures)
+type MapResolver map[string]string
+
+func (r MapResolver) ResolveImport(fromDir, importPath string) (string, error) {
+	return r[importPath], nil
+}
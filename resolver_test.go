@@ -0,0 +1,58 @@
+package atool
+
+import (
+	"github.com/alecthomas/assert"
+	"path/filepath"
+	"testing"
+)
+
+func TestConfig_Resolver(t *testing.T) {
+	mirror := &Config{Resolver: ChainResolver{
+		DirResolver{Prefix: "github.com/shopspring/", Dir: "test/testdata/mirror/github.com/shopspring"},
+		DefaultResolver(),
+	}}
+	f, err := mirror.Scan("test/sample.go")
+	assert.Nil(t, err)
+	ex, err := f.ExtractTypeString("decimal.Decimal")
+	assert.Nil(t, err)
+	assert.Equal(t, "Decimal is a fixture instead of real package\n", ex.Comment)
+	ex, err = f.ExtractTypeString("bytes.Buffer")
+	assert.Nil(t, err)
+	assert.Equal(t, "bytes", ex.File.Import)
+
+	fixtures := &Config{Resolver: MapResolver{"github.com/shopspring/decimal": "test/testdata/mirror/github.com/shopspring/decimal"}}
+	f, err = fixtures.Scan("test/sample.go")
+	assert.Nil(t, err)
+	_, err = f.ExtractTypeString("bytes.Buffer")
+	assert.NotNil(t, err)
+	ex, err = f.ExtractTypeString("decimal.Decimal")
+	assert.Nil(t, err)
+	assert.NotNil(t, ex.Field("Value"))
+
+	var calls []string
+	spy := &Config{Resolver: ResolverFunc(func(fromDir, importPath string) (string, error) {
+		calls = append(calls, importPath)
+		return "", nil
+	})}
+	f, err = spy.Scan("test/testdata/embed/embed.go")
+	assert.Nil(t, err)
+	_, err = f.Interface("Store").AllMethods()
+	assert.NotNil(t, err)
+	assert.Equal(t, []string{"io"}, calls)
+}
+
+func TestDefaultResolver(t *testing.T) {
+	dir, err := DefaultResolver().ResolveImport("test", "go/ast")
+	assert.Nil(t, err)
+	assert.True(t, filepath.IsAbs(dir))
+	assert.Equal(t, "ast", filepath.Base(dir))
+
+	dir, err = DefaultResolver().ResolveImport("test", "github.com/reddec/astools/test")
+	assert.Nil(t, err)
+	root, _ := filepath.Abs("test")
+	assert.Equal(t, root, dir)
+
+	dir, err = DefaultResolver().ResolveImport("test", "example.com/nothing")
+	assert.Nil(t, err)
+	assert.Equal(t, "", dir)
+}
@@ -0,0 +1,108 @@
+package atool
+
+import (
+	"strconv"
+	"strings"
+)
+
+// Tag is a parsed value of one key in struct tag (ex: `json:"name,omitempty"`)
+type Tag struct {
+	Key     string
+	Name    string
+	Options []string `json:",omitempty"`
+	Value   string   `json:"-"` // raw value
+}
+
+// HasOption checks option after name (ex: omitempty)
+func (t *Tag) HasOption(option string) bool {
+	for _, opt := range t.Options {
+		if opt == option {
+			return true
+		}
+	}
+	return false
+}
+
+// IsIgnored checks that field should be skipped by the key (ex: `json:"-"`)
+func (t *Tag) IsIgnored() bool {
+	return t.Value == "-"
+}
+
+// Tags is parsed struct tag in order of keys
+type Tags []*Tag
+
+func (tags Tags) Get(key string) *Tag {
+	for _, t := range tags {
+		if t.Key == key {
+			return t
+		}
+	}
+	return nil
+}
+
+// ParseTag parses struct tag literal (with or without quotes) by the reflect.StructTag convention.
+// Parsing stops on first malformed pair
+func ParseTag(tag string) Tags {
+	if unquoted, err := strconv.Unquote(tag); err == nil {
+		tag = unquoted
+	}
+	var res Tags
+	for tag != "" {
+		i := 0
+		for i < len(tag) && tag[i] == ' ' {
+			i++
+		}
+		tag = tag[i:]
+		if tag == "" {
+			break
+		}
+		i = 0
+		for i < len(tag) && tag[i] > ' ' && tag[i] != ':' && tag[i] != '"' && tag[i] != 0x7f {
+			i++
+		}
+		if i == 0 || i+1 >= len(tag) || tag[i] != ':' || tag[i+1] != '"' {
+			break
+		}
+		key := tag[:i]
+		tag = tag[i+1:]
+
+		i = 1
+		for i < len(tag) && tag[i] != '"' {
+			if tag[i] == '\\' {
+				i++
+			}
+			i++
+		}
+		if i >= len(tag) {
+			break
+		}
+		value, err := strconv.Unquote(tag[:i+1])
+		if err != nil {
+			break
+		}
+		tag = tag[i+1:]
+
+		parts := strings.Split(value, ",")
+		item := &Tag{Key: key, Name: parts[0], Value: value}
+		for _, opt := range parts[1:] {
+			if opt != "" {
+				item.Options = append(item.Options, opt)
+			}
+		}
+		res = append(res, item)
+	}
+	return res
+}
+
+// Tags returns parsed struct tag of field
+func (arg *Arg) Tags() Tags {
+	return ParseTag(arg.Tag)
+}
+
+// TagName returns name from the tag key or field name if key not defined or name is empty
+func (arg *Arg) TagName(key string) string {
+	if tag := arg.Tags().Get(key); tag != nil && tag.Name != "" {
+		return tag.Name
+	}
+	return arg.Name
+}
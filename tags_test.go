@@ -0,0 +1,39 @@
+package atool
+
+import (
+	"encoding/json"
+	"github.com/alecthomas/assert"
+	"testing"
+)
+
+func TestParseTag(t *testing.T) {
+	tags := ParseTag("`json:\"name,omitempty,string\" xml:\"-\" yaml:\",inline\" db:\"a\\\"b\"`")
+	assert.Len(t, tags, 4)
+	js := tags.Get("json")
+	assert.Equal(t, "name", js.Name)
+	assert.Equal(t, []string{"omitempty", "string"}, js.Options)
+	assert.True(t, js.HasOption("omitempty"))
+	assert.False(t, js.IsIgnored())
+	assert.True(t, tags.Get("xml").IsIgnored())
+	assert.Equal(t, "", tags.Get("yaml").Name)
+	assert.True(t, tags.Get("yaml").HasOption("inline"))
+	assert.Equal(t, `a"b`, tags.Get("db").Name)
+	assert.Nil(t, tags.Get("toml"))
+
+	assert.Len(t, ParseTag(`json:"a" broken`), 1)
+	assert.Len(t, ParseTag(""), 0)
+}
+
+func TestArg_Tags(t *testing.T) {
+	f, err := Scan("test/sample.go")
+	assert.Nil(t, err)
+	fuel := f.Struct("Fuel")
+	assert.Equal(t, "Type", fuel.Field("Type").Tags().Get("json").Name)
+	assert.Equal(t, "Type", fuel.Field("Type").TagName("json"))
+	assert.Equal(t, "Amount", fuel.Field("Amount").TagName("json"))
+	assert.Len(t, fuel.Field("Amount").Tags(), 0)
+
+	data, err := json.Marshal(fuel.Field("Type"))
+	assert.Nil(t, err)
+	assert.Contains(t, string(data), `"Tags":[{"Key":"json","Name":"Type"}]`)
+}
@@ -0,0 +1,29 @@
+package annotations
+
+// Launcher starts rockets
+//
+//astools:gen mock
+//astools:gen=client
+//go:generate echo launcher
+type Launcher interface {
+	// Launch the rocket
+	// +rpc:path=/v1/launch
+	// +rpc:method POST
+	Launch(name string) error
+}
+
+// Rocket is not generated
+type Rocket struct {
+	Name  string // +required
+	Power int    //astools:default=10
+}
+
+// Status of launch
+//
+//astools:gen enum
+type Status int
+
+const (
+	Ready Status = iota // +label=ready
+	Done
+)
@@ -0,0 +1,35 @@
+// Package comments has declarations with doc and line comments
+package comments
+
+// Grouped types
+type (
+	// Point is a location
+	Point struct {
+		// X is horizontal
+		X int // pixels
+		Y int // pixels
+	} // point in space
+
+	// Shape could be drawn
+	Shape interface {
+		// Draw the shape
+		Draw(at Point) error // may fail
+	}
+
+	Size int // in pixels
+)
+
+// Origin is a start of coordinates
+var Origin = Point{} // zero point
+
+// Limits of canvas
+const (
+	// MaxWidth is a width limit
+	MaxWidth  Size = 100
+	MaxHeight Size = 200 // height limit
+)
+
+// Render draws all shapes
+func Render(shapes []Shape) error {
+	return nil
+}
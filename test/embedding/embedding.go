@@ -0,0 +1,73 @@
+package embedding
+
+import (
+	"image"
+	"time"
+)
+
+// Base is common part of entities
+type Base struct {
+	ID   string `json:"id"`
+	Name string
+}
+
+// Meta is additional information
+type Meta struct {
+	Name string
+	Tags []string
+}
+
+// Entity embeds local and imported structs
+type Entity struct {
+	*Base
+	Meta `json:"meta"`
+	image.Point
+	Name string // shadows Base.Name and Meta.Name
+}
+
+// Ambiguous has two Name fields on the same depth
+type Ambiguous struct {
+	Base
+	Meta
+	error
+}
+
+// Twice reaches Base twice on the same depth
+type Twice struct {
+	Entity
+	Ambiguous
+}
+
+// Counter is a named non-struct type
+type Counter int
+
+// Derived is based on struct
+type Derived Meta
+
+// Wrapped embeds named types
+type Wrapped struct {
+	Counter
+	time.Duration
+	Derived
+}
+
+// Labeled hides promoted Base.Name by method
+type Labeled struct {
+	Base
+}
+
+// Name of entity
+func (Labeled) Name() string { return "" }
+
+// Titled has method with name of field of embedded Meta
+type Titled struct {
+	Meta
+}
+
+// Tags of title
+func (Titled) Tags() []string { return nil }
+
+// Page reaches Meta.Tags hidden by method of Titled
+type Page struct {
+	Titled
+}
@@ -0,0 +1,33 @@
+package enums
+
+// Status of task
+type Status int
+
+const (
+	// Pending is initial status
+	Pending Status = iota
+	Running        // in progress
+	_
+	Done
+)
+
+// Level is a bit mask
+type Level uint8
+
+const (
+	Low Level = 1 << iota
+	Mid
+	High
+)
+
+type Color string
+
+const Red Color = "red"
+const Green = Color("green")
+
+const (
+	KB = 1 << (10 * (iota + 1))
+	MB
+	Mask = ^uint8(0)
+	Half = MB / 3
+)
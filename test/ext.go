@@ -5,3 +5,8 @@ package sample
 type SampleData struct {
 	X int
 }
+
+// Refill replaces the tank
+func (r *Rocket) Refill(fuel Fuel) {
+	r.Tank = fuel
+}
@@ -0,0 +1,43 @@
+package kinds
+
+import (
+	"io"
+	"net/url"
+	"sync"
+	"time"
+	"unsafe"
+)
+
+type Amount int64
+
+type Money = Amount
+
+type Index map[string]Amount
+
+type IDs []uint32
+
+type Handler func(amount Amount) error
+
+type Kinds struct {
+	Count    uint32
+	Address  uintptr
+	Signal   complex128
+	Amount   Amount
+	Money    Money
+	Index    Index
+	IDs      IDs
+	Handler  Handler
+	Timeout  time.Duration
+	Query    url.Values
+	Lock     sync.Mutex
+	Reader   io.Reader
+	Err      error
+	Events   chan Amount
+	Parent   *Kinds
+	Raw      unsafe.Pointer
+	Checksum [16]byte
+}
+
+func Sum(scale Amount, values ...uint32) Amount {
+	return scale * Amount(len(values))
+}
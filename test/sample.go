@@ -46,3 +46,13 @@ type Control interface {
 type Fs interface {
 	Call(val decimal.Decimal, buffer *bytes.Buffer, sm SampleData)
 }
+
+// NewRocket creates rocket with the default tank
+func NewRocket(name string, power int) (*Rocket, error) {
+	return &Rocket{Name: name, Power: power}, nil
+}
+
+// Empty checks that there is no fuel
+func (f Fuel) Empty() bool {
+	return f.Amount <= 0
+}
@@ -0,0 +1,14 @@
+package broken
+
+// Valid is parsed completely
+type Valid struct {
+	Name string
+}
+
+type Broken struct {
+	Field int
+	Other =
+}
+
+func Run( {
+}
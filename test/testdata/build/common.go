@@ -0,0 +1,10 @@
+package build
+
+import (
+	"net/url"
+)
+
+type Common struct {
+	Platform Platform
+	Location url.URL
+}
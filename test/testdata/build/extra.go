@@ -0,0 +1,5 @@
+//go:build extra
+
+package build
+
+type Extra struct{}
@@ -0,0 +1,7 @@
+//go:build ignore
+
+package main
+
+type Platform struct {
+	Generator bool
+}
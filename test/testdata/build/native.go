@@ -0,0 +1,6 @@
+package build
+
+// #include <stdlib.h>
+import "C"
+
+type Native struct{}
@@ -0,0 +1,5 @@
+package build
+
+type Platform struct {
+	Windows bool
+}
@@ -0,0 +1,31 @@
+package embed
+
+import (
+	"io"
+)
+
+// Base is common for all entities
+type Base interface {
+	ID() string
+}
+
+// Store persists data
+type Store interface {
+	Base
+	io.Closer
+	error
+	Save(data []byte) error
+	// Close is redeclared and should be reported once
+	Close() error
+}
+
+// Closable embeds interface literal
+type Closable interface {
+	interface{ Flush() error }
+	io.Closer
+}
+
+// Broken embeds unknown interface
+type Broken interface {
+	Unknown
+}
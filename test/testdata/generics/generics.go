@@ -0,0 +1,45 @@
+package generics
+
+// Map converts every item by the mapper
+func Map[T any, R any](items []T, mapper func(T) R) []R {
+	var res = make([]R, 0, len(items))
+	for _, item := range items {
+		res = append(res, mapper(item))
+	}
+	return res
+}
+
+// Number is any numeric type
+type Number interface {
+	~int | ~int64 | float64
+}
+
+// Ordered allows comparison
+type Ordered interface {
+	comparable
+	Number
+}
+
+// Pair holds two values
+type Pair[K comparable, V any] struct {
+	Key   K
+	Value V
+}
+
+// Container has items
+type Container[T Number] interface {
+	Items() []T
+}
+
+// Get value by key
+func (p *Pair[K, V]) Get(key K) (V, bool) {
+	return p.Value, p.Key == key
+}
+
+// MyInt is a named non-interface type
+type MyInt int
+
+// Mine is a constraint by named type
+type Mine interface {
+	MyInt
+}
@@ -0,0 +1,45 @@
+package graph
+
+import (
+	"net/url"
+
+	"github.com/reddec/astools/test/testdata/graph/model"
+)
+
+type Service interface {
+	Create(req *CreateRequest) (*model.User, error)
+	List(filter Filter) ([]model.User, error)
+}
+
+type CreateRequest struct {
+	Name   string
+	Links  map[string]*url.URL
+	Parent *Node
+	Page   Page[model.User]
+}
+
+// Node refers to itself
+type Node struct {
+	Value    int
+	Children []*Node
+}
+
+type Page[T any] struct {
+	Items []T
+	Next  *Cursor
+}
+
+type Cursor string
+
+type Filter struct {
+	Owner model.ID
+	Tags  Tags
+	Extra missing.Extra
+}
+
+type Tags []Tag
+
+type Tag struct {
+	Key   string
+	Value string
+}
@@ -0,0 +1,14 @@
+package model
+
+type ID string
+
+// User and Group refer to each other
+type User struct {
+	ID      ID
+	Manager *User
+	Groups  []Group
+}
+
+type Group struct {
+	Owner *User
+}
@@ -0,0 +1,7 @@
+package app
+
+import "github.com/reddec/astools/test/testdata/methods"
+
+type App struct {
+	Current methods.Item
+}
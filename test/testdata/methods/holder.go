@@ -0,0 +1,5 @@
+package methods
+
+type Holder struct {
+	Current Item
+}
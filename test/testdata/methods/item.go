@@ -0,0 +1,5 @@
+package methods
+
+type Item struct {
+	Name string
+}
@@ -0,0 +1,5 @@
+package methods
+
+func (i Item) Title() string { return i.Name }
+
+func (i *Item) Rename(name string) { i.Name = name }
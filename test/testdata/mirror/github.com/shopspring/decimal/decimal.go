@@ -0,0 +1,6 @@
+package decimal
+
+// Decimal is a fixture instead of real package
+type Decimal struct {
+	Value string
+}
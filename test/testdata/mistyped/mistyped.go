@@ -0,0 +1,7 @@
+package mistyped
+
+type Account struct {
+	Owner Person
+}
+
+var Limit int = "unlimited"
@@ -0,0 +1,14 @@
+module example.com/app // application
+
+go 1.21
+
+require (
+	example.com/lib v1.0.0
+	github.com/Masterminds/semver v1.4.2
+	github.com/pkg/errors v0.9.0
+	github.com/shopspring/decimal v0.0.0-20180709203117-cd690d0c9e24 // indirect
+)
+
+replace example.com/lib => ../lib
+
+replace "github.com/pkg/errors" v0.9.0 => github.com/pkg/errors v0.8.1
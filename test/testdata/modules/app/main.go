@@ -0,0 +1,13 @@
+package main
+
+import (
+	"example.com/app/internal/model"
+	"example.com/lib"
+	"github.com/Masterminds/semver"
+)
+
+type App struct {
+	Config  lib.Config
+	Version semver.Version
+	Owner   model.User
+}
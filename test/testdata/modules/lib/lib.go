@@ -0,0 +1,5 @@
+package lib
+
+type Config struct {
+	Debug bool
+}
@@ -0,0 +1,11 @@
+package api
+
+import "github.com/reddec/astools/test/testdata/patterns/model"
+
+type Request struct {
+	User model.User
+}
+
+type Service interface {
+	Save(req Request) error
+}
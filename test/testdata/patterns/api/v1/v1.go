@@ -0,0 +1,6 @@
+package v1
+
+type Version struct {
+	Major int
+	Minor int
+}
@@ -0,0 +1,42 @@
+package refs
+
+import (
+	"io"
+	"net/url"
+	"time"
+)
+
+type List[T any] struct {
+	Items []T
+}
+
+type Pair[K comparable, V any] struct {
+	Key   K
+	Value V
+}
+
+type Refs struct {
+	Index    map[string]*url.URL
+	Location *url.URL
+	Pointers []*time.Time
+	Buffer   [4]byte
+	Handler  func(io.Reader, ...string) (int, error)
+	Events   chan<- time.Duration
+	Updates  <-chan []string
+	Nested   chan (<-chan int)
+	Inline   struct {
+		io.Writer
+		Name string `json:"name"`
+	}
+	Closer interface {
+		io.Closer
+		Flush(force bool) error
+	}
+	Times List[time.Time]
+	Split func() (quo, rem int)
+	Pairs []Pair[string, *url.URL]
+}
+
+var Timeout time.Duration = time.Second
+
+type Callback func(ctx map[string]interface{}) (res []byte, err error)
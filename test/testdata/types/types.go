@@ -0,0 +1,28 @@
+package types
+
+import (
+	"net/http"
+)
+
+// Status of task
+type Status int
+
+// Handler processes request
+type Handler func(w http.ResponseWriter, r *http.Request) error
+
+type (
+	// Handlers by path
+	Handlers map[string]Handler
+	IDs      []string
+	Header   = http.Header
+	Set[T comparable] map[T]struct{}
+)
+
+// Task is not a named type
+type Task struct {
+	Status Status
+}
+
+func (s Status) String() string {
+	return "status"
+}
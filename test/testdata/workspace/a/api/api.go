@@ -0,0 +1,12 @@
+package api
+
+import (
+	"example.com/b/model"
+	"example.com/ext"
+)
+
+// Request refers types from other modules of workspace
+type Request struct {
+	User  model.User
+	Extra ext.Extra
+}
@@ -0,0 +1,10 @@
+module example.com/a
+
+go 1.21
+
+require (
+	example.com/b v0.1.0
+	example.com/ext v1.0.0
+)
+
+replace example.com/ext => ../missing
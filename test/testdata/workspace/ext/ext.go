@@ -0,0 +1,5 @@
+package ext
+
+type Extra struct {
+	Value string
+}
@@ -0,0 +1,26 @@
+package typed
+
+import (
+	"net/url"
+	"time"
+)
+
+// Failure is an error with code
+type Failure int
+
+func (f Failure) Error() string { return "failure" }
+
+type Account struct {
+	Name    string
+	Created time.Time
+	Link    *url.URL
+	Tags    []string
+	Limits  map[string]int
+	Reason  Failure
+}
+
+func (a *Account) String() string { return a.Name }
+
+func Open(link *url.URL, timeout time.Duration) (*Account, error) {
+	return &Account{Link: link}, nil
+}
@@ -0,0 +1,4 @@
+package values
+
+// C is computed from constants of another file
+const C = A + B + 10
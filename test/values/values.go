@@ -0,0 +1,19 @@
+package values
+
+import (
+	"strconv"
+)
+
+const A, B = 1, 2
+
+const (
+	X float64 = 1.5
+	Y
+	Z = X * 2
+)
+
+var (
+	Name          = "astools"
+	Count, Total  int
+	Parsed, Error = strconv.Atoi("42")
+)
@@ -5,25 +5,34 @@ import (
 	"fmt"
 	"github.com/pkg/errors"
 	"go/ast"
+	"go/constant"
 	"go/parser"
 	"go/token"
+	"go/types"
 	"io/ioutil"
-	"os"
 	"path/filepath"
+	"reflect"
 	"strings"
+	"sync"
 )
 
 type Struct struct {
-	Name       string
-	Comment    string `json:",omitempty"`
-	Fields     []*Arg
-	Definition *ast.StructType `json:"-"`
-	printer    *Printer        `json:"-"`
-	File       *File           `json:"-"`
+	Name        string
+	Comment     string      `json:",omitempty"` // combined view of all comments
+	Doc         string      `json:",omitempty"`
+	LineComment string      `json:",omitempty"`
+	Annotations Annotations `json:",omitempty"`
+	TypeParams  []*Arg      `json:",omitempty"`
+	Fields      []*Arg
+	Methods     []*Method       `json:",omitempty"` // methods declared with the struct as receiver
+	Definition  *ast.StructType `json:"-"`
+	Position    *Position       `json:",omitempty"`
+	printer     *Printer        `json:"-"`
+	File        *File           `json:"-"`
 }
 
 func (s *Struct) GoLang() string {
-	return "type " + s.Name + " " + s.printer.ToString(s.Definition)
+	return "type " + s.Name + typeParamsString(s.TypeParams) + " " + s.printer.ToString(s.Definition)
 }
 
 func (s *Struct) Field(name string) *Arg {
@@ -36,16 +45,21 @@ func (s *Struct) Field(name string) *Arg {
 }
 
 func StructsFile(filename string) ([]*Struct, *Printer, error) {
-	tokens := token.NewFileSet()
-	file, err := parser.ParseFile(tokens, filename, nil, parser.AllErrors|parser.ParseComments)
+	content, err := ioutil.ReadFile(filename)
 	if err != nil {
 		return nil, nil, err
 	}
-	content, err := ioutil.ReadFile(filename)
+	return StructsSource(filename, content)
+}
+
+// StructsSource is same as StructsFile but for the source in memory
+func StructsSource(name string, content []byte) ([]*Struct, *Printer, error) {
+	tokens := token.NewFileSet()
+	file, err := parser.ParseFile(tokens, name, content, parser.AllErrors|parser.ParseComments)
 	if err != nil {
 		return nil, nil, err
 	}
-	printer := &Printer{string(content), ast.NewCommentMap(tokens, file, file.Comments)}
+	printer := newPrinter(tokens, file, content)
 	var res []*Struct
 	for _, node := range file.Decls {
 		res = append(res, Structs(printer, node)...)
@@ -56,24 +70,39 @@ func StructsFile(filename string) ([]*Struct, *Printer, error) {
 func Structs(printer *Printer, decls ...ast.Node) []*Struct {
 	var res []*Struct
 	var stack []ast.Node
-	var name string
+	var spec *ast.TypeSpec
+	var typeParams []*Arg
 	for i := len(decls) - 1; i >= 0; i-- {
 		stack = append(stack, decls[i])
 	}
 
 	var lastComment string
+	var lastDecl *ast.GenDecl
 	for len(stack) > 0 {
 		node := stack[len(stack)-1]
 		stack = stack[:len(stack)-1]
 
 		switch v := node.(type) {
 		case *ast.TypeSpec:
-			name = v.Name.Name
+			spec = v
+			typeParams = getTypeParams(printer, v.TypeParams)
 			stack = append(stack, v.Type)
 		case *ast.StructType:
-			res = append(res, &Struct{name, lastComment, getArgs(printer, v.Fields.List), v, printer, nil})
+			res = append(res, &Struct{
+				Name:        spec.Name.Name,
+				Comment:     lastComment,
+				Doc:         specDoc(lastDecl, spec.Doc).Text(),
+				LineComment: spec.Comment.Text(),
+				Annotations: printer.annotations(specDoc(lastDecl, spec.Doc), spec.Comment),
+				TypeParams:  typeParams,
+				Fields:      getFields(printer, v.Fields.List),
+				Definition:  v,
+				Position:    printer.position(spec.Name, spec),
+				printer:     printer,
+			})
 		case *ast.GenDecl:
 			lastComment = joinComments(printer.CommentMap[v])
+			lastDecl = v
 			for _, spec := range v.Specs {
 				stack = append(stack, spec)
 			}
@@ -83,36 +112,62 @@ func Structs(printer *Printer, decls ...ast.Node) []*Struct {
 }
 
 type Arg struct {
-	Name    string
-	Type    ast.Expr
-	Tag     string
-	Comment string
-	printer *Printer
-	field   *ast.Field
+	Name        string
+	Type        ast.Expr
+	Tag         string
+	Comment     string // combined view of all comments
+	Doc         string
+	LineComment string
+	Annotations Annotations
+	IsEmbedded  bool // anonymous struct field, name is a type name
+	Position    *Position
+	printer     *Printer
+	field       *ast.Field
 }
 
 func (u *Arg) AsField() *ast.Field { return u.field }
 
+// Ref returns tree of argument type
+func (u *Arg) Ref() *TypeRef { return AsTypeRef(u.Type, u.printer) }
+
+// GoPkgType returns package alias and name of named type (pointers are skipped).
+// Other types have no package and returned as is (ex: "", "map[string]pkg.T")
 func (u *Arg) GoPkgType() (string, string) {
-	v := strings.Split(u.GolangType(), ".")
-	if len(v) > 1 {
-		return v[0], v[1]
+	ref := u.Ref().Deref()
+	if ref.IsNamed() {
+		return ref.Package, ref.Name
 	}
-	return "", v[0]
+	return "", ref.String()
 }
 
 func (u *Arg) MarshalJSON() ([]byte, error) {
 	return json.Marshal(&struct {
-		Name       string
-		GolangType string
-		Comment    string `json:",omitempty"`
-		IsError    bool
+		Name        string
+		GolangType  string
+		Comment     string      `json:",omitempty"`
+		Doc         string      `json:",omitempty"`
+		LineComment string      `json:",omitempty"`
+		Annotations Annotations `json:",omitempty"`
+		IsError     bool
+		IsEmbedded  bool      `json:",omitempty"`
+		Tags        Tags      `json:",omitempty"`
+		Position    *Position `json:",omitempty"`
+		Ref         *TypeRef  `json:",omitempty"`
+		TypeString  string    `json:",omitempty"` // only in typed mode
 	}{
 
-		Name:       u.Name,
-		GolangType: u.GolangType(),
-		Comment:    u.Comment,
-		IsError:    u.IsError(),
+		Name:        u.Name,
+		GolangType:  u.GolangType(),
+		Comment:     u.Comment,
+		Doc:         u.Doc,
+		LineComment: u.LineComment,
+		Annotations: u.Annotations,
+		IsError:     u.IsError(),
+		IsEmbedded:  u.IsEmbedded,
+		Tags:        u.Tags(),
+		Position:    u.Position,
+		Ref:         u.Ref(),
+		TypeString:  u.TypeString(),
 	})
 }
 
@@ -121,80 +176,60 @@ func (arg *Arg) IsPointer() bool {
 	return ok
 }
 
+// IsSimple checks that underlying type is a predeclared boolean, numeric or string type
 func (arg *Arg) IsSimple() bool {
-	v, ok := arg.Type.(*ast.Ident)
-	if ok {
-		switch (v.Name) {
-		case "byte", "rune", "int", "int8", "int16", "int32", "int64", "uint", "uint8", "uint16", "uint64", "string", "bool", "float32", "float64":
-			return true
-		}
-	}
-	return false
+	kind := arg.Kind()
+	return kind != reflect.Invalid && kind <= reflect.Complex128 || kind == reflect.String
 }
 
 func (arg *Arg) IsInteger() bool {
-	v, ok := arg.Type.(*ast.Ident)
-	if ok {
-		switch (v.Name) {
-		case "byte", "int", "int8", "int16", "int32", "int64", "uint", "uint8", "uint16", "uint64":
-			return true
-		}
-	}
-	return false
+	kind := arg.Kind()
+	return kind >= reflect.Int && kind <= reflect.Uintptr
 }
 
 func (arg *Arg) IsFloat() bool {
-	v, ok := arg.Type.(*ast.Ident)
-	if ok {
-		switch (v.Name) {
-		case "float32", "float64":
-			return true
-		}
-	}
-	return false
+	kind := arg.Kind()
+	return kind == reflect.Float32 || kind == reflect.Float64
 }
 
 func (arg *Arg) IsString() bool {
-	v, ok := arg.Type.(*ast.Ident)
-	if ok {
-		switch (v.Name) {
-		case "string":
-			return true
-		}
-	}
-	return false
+	return arg.Kind() == reflect.String
 }
 
 func (arg *Arg) IsBoolean() bool {
-	v, ok := arg.Type.(*ast.Ident)
-	if ok {
-		switch (v.Name) {
-		case "bool":
-			return true
-		}
-	}
-	return false
+	return arg.Kind() == reflect.Bool
 }
+
+// IsArray checks that underlying type is slice or array
 func (arg *Arg) IsArray() bool {
-	_, ok := arg.Type.(*ast.ArrayType)
-	return ok
+	kind := arg.Kind()
+	return kind == reflect.Slice || kind == reflect.Array
 }
+
+// ArrayItem returns element of slice, array (including named types) or variadic parameter or nil
 func (arg *Arg) ArrayItem() *Arg {
-	v, ok := arg.Type.(*ast.ArrayType)
-	if !ok {
+	tp, printer := underlying(arg.Type, arg.printer)
+	var elem ast.Expr
+	switch v := tp.(type) {
+	case *ast.ArrayType:
+		elem = v.Elt
+	case *ast.Ellipsis:
+		elem = v.Elt
+	default:
 		return nil
 	}
 	return &Arg{
 		Name:    "",
-		Type:    v.Elt,
+		Type:    elem,
 		Comment: "",
-		printer: arg.printer,
+		printer: printer,
 	}
 }
+
 func (arg *Arg) IsMap() bool {
-	_, ok := arg.Type.(*ast.MapType)
-	return ok
+	return arg.Kind() == reflect.Map
 }
+
 func (arg *Arg) IsError() bool {
 	v, ok := arg.Type.(*ast.Ident)
 	return ok && v.Name == "error"
@@ -203,35 +238,21 @@ func (file *File) ExtractType(tp ast.Expr) (*Struct, error) {
 	return file.ExtractTypeString(file.Printer.ToString(tp))
 }
 
-func findVendorDir(dir string) string {
-	vendorDir := filepath.Join(dir, "vendor")
-	st, err := os.Stat(vendorDir)
-	if !os.IsNotExist(err) && err != nil {
-		return ""
-	} else if err == nil && st.IsDir() {
-		pth, err := filepath.Abs(vendorDir)
-		if err != nil {
-			panic(err)
-		}
-		return pth
-	}
-	up, err := filepath.Abs(filepath.Join(dir, ".."))
+func (file *File) ExtractTypeString(tp string) (*Struct, error) {
+	src, name, err := file.lookupType(tp, func(f *File, name string) bool { return f.Struct(name) != nil })
 	if err != nil {
-		panic(err)
-	}
-	if up == dir {
-		return ""
+		return nil, err
 	}
-	return findVendorDir(up)
+	return src.Struct(name), nil
 }
 
-func (file *File) ExtractTypeString(tp string) (*Struct, error) {
+// lookupType finds the file (this one, a sibling or a file of imported package) where the type is declared.
+// Declaration is detected by the has function
+func (file *File) lookupType(tp string, has func(f *File, name string) bool) (*File, string, error) {
 	tp = strings.Replace(tp, "*", "", -1)
 
-	for _, s := range file.Structs {
-		if s.Name == tp {
-			return s, nil
-		}
+	if has(file, tp) {
+		return file, tp, nil
 	}
 
 	tpPkg := "_"
@@ -241,100 +262,147 @@ func (file *File) ExtractTypeString(tp string) (*Struct, error) {
 		tpPkg = sp[0]
 		tp = sp[1]
 	}
-	if tpPkg == "_" && file.near == nil {
-		dirName := filepath.Dir(file.location)
-		dir, err := ioutil.ReadDir(dirName)
+	if tpPkg == "_" {
+		near, err := file.siblings()
 		if err != nil {
-			return nil, err
-		}
-		for _, fileName := range dir {
-			if fileName.IsDir() {
-				continue
-			}
-			if filepath.Ext(fileName.Name()) == ".go" {
-				childFile, err := Scan(filepath.Join(dirName, fileName.Name()))
-				if err != nil {
-					return nil, err
-				}
-				childFile.Import = file.Import
-				file.near = append(file.near, childFile)
-				// on-fly check type
-				if s := childFile.Struct(tp); s != nil {
-					return s, nil
-				}
-			}
+			return nil, "", err
 		}
-	} else if tpPkg == "_" {
-		for _, childFile := range file.near {
-			if s := childFile.Struct(tp); s != nil {
-				return s, nil
+		for _, childFile := range near {
+			if has(childFile, tp) {
+				return childFile, tp, nil
 			}
 		}
 	}
 
 	for imp, alias := range file.Imports {
 
-		if alias != tpPkg && alias != "" {
+		// local types are never declared in imported packages
+		if tpPkg == "_" || (alias != tpPkg && alias != "") {
 			continue
 		}
 		imp = strings.Replace(imp, "\"", "", -1)
 
-		vendorDir := findVendorDir(filepath.Dir(file.location))
-		for _, fileOptions := range []string{filepath.Join(vendorDir, imp), filepath.Join(os.Getenv("GOPATH"), "src", imp), filepath.Join(os.Getenv("GOROOT"), "src", imp)} {
-			var localPath string
-			if fileOptions == "" {
-				continue
-			}
-			if st, err := os.Lstat(fileOptions); err != nil || !st.IsDir() {
-				continue
-			} else {
-				localPath = fileOptions
-			}
-			files, err := ioutil.ReadDir(localPath)
+		localPath, err := file.cfg().resolver().ResolveImport(filepath.Dir(file.location), imp)
+		if err != nil {
+			return nil, "", errors.Wrapf(err, "resolve %v", imp)
+		}
+		if localPath == "" {
+			continue
+		}
+		if name := file.cfg().importName(localPath); alias == "" && name != "" && name != tpPkg {
+			continue // name of package is known by cache and doesn't match
+		}
+		files, err := file.scanImport(localPath, imp)
+		if err != nil {
+			return nil, "", errors.Wrapf(err, "scan dir %v", localPath)
+		}
 
-			if err != nil {
-				return nil, errors.Wrapf(err, "scan dir %v", localPath)
+		for _, nxtFile := range files {
+			if alias != "_" && (alias == tpPkg || nxtFile.Package == tpPkg) {
+				src, name, err := nxtFile.lookupType(tp, has)
+				if err == nil {
+					return src, name, nil
+				}
+				break // siblings are already checked
 			}
+		}
+	}
+	return nil, "", errors.New("type " + tp + " can't be extracted")
+}
 
-			for _, fileInfo := range files {
-				if fileInfo.IsDir() {
-					continue
-				}
-				fileName := filepath.Join(localPath, fileInfo.Name())
+// lookupCache keeps resolved types and scanned imports of file, so repeated lookups don't parse sources again
+type lookupCache struct {
+	lock    sync.Mutex
+	named   map[string]*namedLookup // by type name
+	imports map[string][]*File      // by directory
+}
 
-				if !strings.HasSuffix(fileName, ".go") {
-					continue
-				}
-				if strings.HasSuffix(fileName, "_test.go") {
-					continue
-				}
-				nxtFile, err := Scan(fileName)
-				if err != nil {
-					return nil, errors.Wrapf(err, "scan source %v", fileName)
-				}
-				if alias != "_" && nxtFile.Package == tpPkg {
-					nxtFile.Import = imp
-					tpTp, err := nxtFile.ExtractTypeString(tp)
-					if err == nil {
-						return tpTp, nil
-					}
-				}
-			}
+type namedLookup struct {
+	named Named
+	err   error
+}
+
+// siblings returns files in the same directory, scanned once on demand
+func (file *File) siblings() ([]*File, error) {
+	file.lookups.lock.Lock()
+	defer file.lookups.lock.Unlock()
+	if file.near != nil {
+		return file.near, nil
+	}
+	near, err := file.cfg().scanDir(token.NewFileSet(), filepath.Dir(file.location))
+	if err != nil {
+		return nil, err
+	}
+	for i, childFile := range near {
+		if childFile.location == file.location {
+			near[i] = file
+			continue
 		}
+		childFile.Import = file.Import
+		childFile.near = near
 	}
-	return nil, errors.New("type " + tp + " can't be extracted")
+	file.near = near
+	attachPackageMethods(near, file)
+	return near, nil
+}
+
+// scanImport returns files of imported package, scanned once per file (or shared by cache of config)
+func (file *File) scanImport(dir, importPath string) ([]*File, error) {
+	file.lookups.lock.Lock()
+	files, ok := file.lookups.imports[dir]
+	file.lookups.lock.Unlock()
+	if ok {
+		return files, nil
+	}
+	files, err := file.cfg().scanImport(dir, importPath)
+	if err != nil {
+		return nil, err
+	}
+	file.lookups.lock.Lock()
+	if file.lookups.imports == nil {
+		file.lookups.imports = make(map[string][]*File)
+	}
+	file.lookups.imports[dir] = files
+	file.lookups.lock.Unlock()
+	return files, nil
 }
 
 func (arg *Arg) GolangType() string {
 	return arg.printer.ToString(arg.Type)
 }
 
+// ValueKind is a kind of declaration: const or var
+type ValueKind string
+
+const (
+	Const ValueKind = "const"
+	Var   ValueKind = "var"
+)
+
 type Value struct {
-	Name    string
-	Type    ast.Expr
-	Comment string
-	printer *Printer
-	Value   ast.Expr
+	Name        string
+	Type        ast.Expr
+	Comment     string // combined view of all comments
+	Doc         string
+	LineComment string
+	Annotations Annotations
+	Kind        ValueKind
+	printer     *Printer
+	Value       ast.Expr
+	Evaluated   constant.Value // value of constant if it can be computed, otherwise nil
+	Position    *Position
+}
+
+func (arg *Value) IsConst() bool {
+	return arg.Kind == Const
+}
+
+// EvaluatedValue returns computed value of constant as Go literal or empty string
+func (arg *Value) EvaluatedValue() string {
+	if arg.Evaluated == nil || arg.Evaluated.Kind() == constant.Unknown {
+		return ""
+	}
+	return arg.Evaluated.ExactString()
 }
 
 func (arg *Value) GolangValue() string {
@@ -343,18 +411,32 @@ func (arg *Value) GolangValue() string {
 
 func (u *Value) MarshalJSON() ([]byte, error) {
 	return json.Marshal(&struct {
-		Name        string
-		GolangType  string
-		Comment     string `json:",omitempty"`
-		GolangValue string
-		IsError     bool
+		Name           string
+		Kind           ValueKind `json:",omitempty"`
+		GolangType     string
+		Comment        string      `json:",omitempty"`
+		Doc            string      `json:",omitempty"`
+		LineComment    string      `json:",omitempty"`
+		Annotations    Annotations `json:",omitempty"`
+		GolangValue    string
+		EvaluatedValue string `json:",omitempty"`
+		IsError        bool
+		Position       *Position `json:",omitempty"`
+		Ref            *TypeRef  `json:",omitempty"`
 	}{
 
-		Name:        u.Name,
-		GolangType:  u.GolangType(),
-		GolangValue: u.GolangValue(),
-		Comment:     u.Comment,
-		IsError:     u.IsError(),
+		Name:           u.Name,
+		Kind:           u.Kind,
+		GolangType:     u.GolangType(),
+		GolangValue:    u.GolangValue(),
+		EvaluatedValue: u.EvaluatedValue(),
+		Comment:        u.Comment,
+		Doc:            u.Doc,
+		LineComment:    u.LineComment,
+		Annotations:    u.Annotations,
+		IsError:        u.IsError(),
+		Position:       u.Position,
+		Ref:            u.Ref(),
 	})
 }
 
@@ -363,15 +445,25 @@ func (arg *Value) IsError() bool {
 	return ok && v.Name == "error"
 }
 
+// Ref returns tree of value type or nil if type is not declared
+func (arg *Value) Ref() *TypeRef { return AsTypeRef(arg.Type, arg.printer) }
+
 func (arg *Value) GolangType() string {
 	return arg.printer.ToString(arg.Type)
 }
 
 type Method struct {
-	Name    string
-	Comment string `json:",omitempty"`
-	In      []*Arg `json:",omitempty"`
-	Out     []*Arg `json:",omitempty"`
+	Name        string
+	Comment     string      `json:",omitempty"` // combined view of all comments
+	Doc         string      `json:",omitempty"`
+	LineComment string      `json:",omitempty"`
+	Annotations Annotations `json:",omitempty"`
+	In          []*Arg      `json:",omitempty"`
+	Out         []*Arg      `json:",omitempty"`
+	Receiver    *Arg        `json:",omitempty"` // defined only for methods declared with receiver
+	Position    *Position   `json:",omitempty"`
+	ident       *ast.Ident
+	printer     *Printer
 }
 
 func (m *Method) HasInput() bool {
@@ -403,22 +495,53 @@ func (m *Method) NonErrorOutputs() []*Arg {
 }
 
 type Interface struct {
-	Name       string
-	Methods    []*Method          `json:",omitempty"`
-	Comment    string             `json:",omitempty"`
-	Definition *ast.InterfaceType `json:"-"`
+	Name        string
+	TypeParams  []*Arg             `json:",omitempty"`
+	Methods     []*Method          `json:",omitempty"`
+	Embedded    []*Arg             `json:",omitempty"` // embedded interfaces
+	Unions      []Union            `json:",omitempty"` // type elements of constraint interface
+	Comment     string             `json:",omitempty"` // combined view of all comments
+	Doc         string             `json:",omitempty"`
+	LineComment string             `json:",omitempty"`
+	Annotations Annotations        `json:",omitempty"`
+	Definition  *ast.InterfaceType `json:"-"`
+	Position    *Position          `json:",omitempty"`
+	File        *File              `json:"-"`
+	printer     *Printer
+}
+
+func (in *Interface) GoLang() string {
+	return "type " + in.Name + typeParamsString(in.TypeParams) + " " + in.printer.ToString(in.Definition)
 }
 
 type Printer struct {
 	Src        string
 	CommentMap ast.CommentMap
+	file       *token.File // position base of Src in the file set, nil means the file is alone in the set
+	source     *File       // scanned file of the source, used to resolve named types
+	prefixes   []string    // markers of annotations, all markers are used if empty
+}
+
+func newPrinter(tokens *token.FileSet, file *ast.File, content []byte) *Printer {
+	return &Printer{
+		Src:        string(content),
+		CommentMap: ast.NewCommentMap(tokens, file, file.Comments),
+		file:       tokens.File(file.Pos()),
+	}
 }
 
 func (p *Printer) ToString(node ast.Node) string {
 	if node == nil {
 		return ""
 	}
-	return p.Src[node.Pos()-1 : node.End()-1]
+	return p.Src[p.offset(node.Pos()):p.offset(node.End())]
+}
+
+func (p *Printer) offset(pos token.Pos) int {
+	if p.file == nil {
+		return int(pos) - 1
+	}
+	return int(pos) - p.file.Base()
 }
 
 func (in *Interface) Method(name string) *Method {
@@ -436,15 +559,35 @@ type File struct {
 	Comment    string
 	Imports    map[string]string
 	Values     []*Value
-	Interfaces []*Interface `json:",omitempty"`
-	Structs    []*Struct    `json:",omitempty"`
-	Printer    *Printer     `json:"-"`
-	near       []*File // files in the same directory
+	Interfaces []*Interface  `json:",omitempty"`
+	Structs    []*Struct     `json:",omitempty"`
+	Funcs      []*Func       `json:",omitempty"`
+	Types      []*NamedType  `json:",omitempty"`
+	Enums      []*Enum       `json:",omitempty"`
+	Printer    *Printer      `json:"-"`
+	methods    []*Method     // all methods with receivers
+	consts     []*constEntry // all constants with implicit values
+	config     *Config       // configuration used to scan file, siblings and imports
+	near       []*File       // files in the same directory
+	lookups    *lookupCache  // results of lookups, shared by copies of file
 	location   string
+	syntax     *ast.File
+	// problems of source, defined only in partial mode
+	Diagnostics []*Diagnostic `json:",omitempty"`
+	// defined only in typed mode
+	TypesPackage *types.Package `json:"-"`
+	TypesInfo    *types.Info    `json:"-"`
 }
 
 func (f *File) Location() string { return f.location }
 
+func (f *File) cfg() *Config {
+	if f.config == nil {
+		return defaultConfig
+	}
+	return f.config
+}
+
 func (f *File) WithImports(names ... string) map[string]string {
 	var res = make(map[string]string)
 	for path, alias := range f.Imports {
@@ -484,18 +627,56 @@ func (f *File) Struct(name string) *Struct {
 	return nil
 }
 
+func (f *File) Type(name string) *NamedType {
+	for _, v := range f.Types {
+		if v.Name == name {
+			return v
+		}
+	}
+	return nil
+}
+
+func (f *File) Enum(name string) *Enum {
+	for _, v := range f.Enums {
+		if v.Name == name {
+			return v
+		}
+	}
+	return nil
+}
+
+func (f *File) Func(name string) *Func {
+	for _, v := range f.Funcs {
+		if v.Name == name {
+			return v
+		}
+	}
+	return nil
+}
+
 func Scan(filename string) (*File, error) {
-	tokens := token.NewFileSet()
-	file, err := parser.ParseFile(tokens, filename, nil, parser.AllErrors|parser.ParseComments)
+	return defaultConfig.Scan(filename)
+}
+
+func (cfg *Config) scanFile(tokens *token.FileSet, filename string) (*File, error) {
+	content, err := cfg.readFile(filename)
 	if err != nil {
 		return nil, err
 	}
-	content, err := ioutil.ReadFile(filename)
-	if err != nil {
+	return cfg.scanSource(tokens, filename, content)
+}
+
+func (cfg *Config) scanSource(tokens *token.FileSet, filename string, content []byte) (*File, error) {
+	file, err := parser.ParseFile(tokens, filename, content, parser.AllErrors|parser.ParseComments)
+	var diagnostics []*Diagnostic
+	if err != nil && (!cfg.Partial || file == nil) {
 		return nil, err
+	} else if err != nil {
+		diagnostics = parseDiagnostics(filename, err)
 	}
 
-	printer := &Printer{string(content), ast.NewCommentMap(tokens, file, file.Comments)}
+	printer := newPrinter(tokens, file, content)
+	printer.prefixes = cfg.AnnotationPrefixes
 
 	var structs []*Struct
 	for _, node := range file.Decls {
@@ -507,12 +688,27 @@ func Scan(filename string) (*File, error) {
 		interfaces = append(interfaces, Interfaces(printer, node)...)
 	}
 
+	var types []*NamedType
+	for _, node := range file.Decls {
+		types = append(types, Types(printer, node)...)
+	}
+
 	var constants []*Value
 	for _, node := range file.Decls {
-		for _, v := range Values(printer, node) {
-			constants = append(constants, v)
+		constants = append(constants, ValuesList(printer, node)...)
+	}
+
+	var funcs []*Func
+	var methods []*Method
+	var consts []*constEntry
+	for _, node := range file.Decls {
+		funcs = append(funcs, Funcs(printer, node)...)
+		methods = append(methods, Methods(printer, node)...)
+		if decl, ok := node.(*ast.GenDecl); ok {
+			consts = append(consts, constEntries(printer, decl)...)
 		}
 	}
+	attachMethods(structs, types, methods)
 
 	imports := make(map[string]string)
 	for _, imp := range file.Imports {
@@ -523,32 +719,57 @@ func Scan(filename string) (*File, error) {
 		imports[imp.Path.Value] = alias
 	}
 	fs := &File{
-		Package:    file.Name.Name,
-		Printer:    printer,
-		Structs:    structs,
-		Interfaces: interfaces,
-		Imports:    imports,
-		Values:     constants,
-		Comment:    joinComments(printer.CommentMap[file]),
-		location:   filename,
+		Package:     file.Name.Name,
+		Printer:     printer,
+		Structs:     structs,
+		Interfaces:  interfaces,
+		Imports:     imports,
+		Values:      constants,
+		Funcs:       funcs,
+		Types:       types,
+		methods:     methods,
+		consts:      consts,
+		Comment:     joinComments(printer.CommentMap[file]),
+		location:    filename,
+		config:      cfg,
+		lookups:     &lookupCache{},
+		syntax:      file,
+		Diagnostics: diagnostics,
 	}
+	printer.source = fs
 	for _, st := range fs.Structs {
 		st.File = fs
 	}
+	for _, fn := range fs.Funcs {
+		fn.File = fs
+	}
+	for _, iface := range fs.Interfaces {
+		iface.File = fs
+	}
+	for _, tp := range fs.Types {
+		tp.File = fs
+	}
+	fs.Enums = getEnums(consts, fs.Type)
+	evaluateValues(fs.Values, consts)
 	return fs, nil
 }
 
 func InterfacesFile(filename string) ([]*Interface, *Printer, error) {
-	tokens := token.NewFileSet()
 	content, err := ioutil.ReadFile(filename)
 	if err != nil {
 		return nil, nil, err
 	}
-	file, err := parser.ParseFile(tokens, filename, nil, parser.AllErrors|parser.ParseComments)
-	printer := &Printer{string(content), ast.NewCommentMap(tokens, file, file.Comments)}
+	return InterfacesSource(filename, content)
+}
+
+// InterfacesSource is same as InterfacesFile but for the source in memory
+func InterfacesSource(name string, content []byte) ([]*Interface, *Printer, error) {
+	tokens := token.NewFileSet()
+	file, err := parser.ParseFile(tokens, name, content, parser.AllErrors|parser.ParseComments)
 	if err != nil {
 		return nil, nil, err
 	}
+	printer := newPrinter(tokens, file, content)
 	var res []*Interface
 	for _, node := range file.Decls {
 		res = append(res, Interfaces(printer, node)...)
@@ -559,30 +780,38 @@ func InterfacesFile(filename string) ([]*Interface, *Printer, error) {
 func Interfaces(printer *Printer, decls ...ast.Node) []*Interface {
 	var res []*Interface
 	var stack []ast.Node
-	var name string
+	var spec *ast.TypeSpec
+	var typeParams []*Arg
 	for i := len(decls) - 1; i >= 0; i-- {
 		stack = append(stack, decls[i])
 	}
 	var lastComment string
+	var lastDecl *ast.GenDecl
 	for len(stack) > 0 {
 		node := stack[len(stack)-1]
 		stack = stack[:len(stack)-1]
 		switch v := node.(type) {
 		case *ast.TypeSpec:
-			name = v.Name.Name
+			spec = v
+			typeParams = getTypeParams(printer, v.TypeParams)
 			stack = append(stack, v.Type)
 		case *ast.InterfaceType:
-			iface := &Interface{Name: name, Definition: v, Comment: lastComment}
-			for _, m := range v.Methods.List {
-				// skip anonym method - in progress
-				if len(m.Names) == 0 {
-					continue
-				}
-				iface.Methods = append(iface.Methods, AsMethod(m, printer))
+			iface := &Interface{
+				Name:        spec.Name.Name,
+				Definition:  v,
+				TypeParams:  typeParams,
+				Comment:     lastComment,
+				Doc:         specDoc(lastDecl, spec.Doc).Text(),
+				LineComment: spec.Comment.Text(),
+				Annotations: printer.annotations(specDoc(lastDecl, spec.Doc), spec.Comment),
+				Position:    printer.position(spec.Name, spec),
+				printer:     printer,
 			}
+			iface.addElements(v)
 			res = append(res, iface)
 		case *ast.GenDecl:
 			lastComment = joinComments(printer.CommentMap[v])
+			lastDecl = v
 			for _, spec := range v.Specs {
 				stack = append(stack, spec)
 			}
@@ -591,10 +820,48 @@ func Interfaces(printer *Printer, decls ...ast.Node) []*Interface {
 	return res
 }
 
+// addElements collects methods, embedded types and unions of interface type
+func (in *Interface) addElements(v *ast.InterfaceType) {
+	printer := in.printer
+	for _, m := range v.Methods.List {
+		if len(m.Names) == 0 && isUnion(m.Type) {
+			in.Unions = append(in.Unions, getUnion(printer, m.Type))
+			continue
+		}
+		if _, ok := m.Type.(*ast.FuncType); !ok && len(m.Names) > 0 {
+			continue // broken method of partially parsed source
+		}
+		if len(m.Names) == 0 {
+			in.Embedded = append(in.Embedded, &Arg{
+				Name:        embeddedName(m.Type),
+				Type:        m.Type,
+				Comment:     joinComments(printer.CommentMap[m]),
+				Doc:         m.Doc.Text(),
+				LineComment: m.Comment.Text(),
+				Annotations: printer.annotations(m.Doc, m.Comment),
+				Position:    printer.position(nil, m),
+				printer:     printer,
+				field:       m,
+			})
+			continue
+		}
+		in.Methods = append(in.Methods, AsMethod(m, printer))
+	}
+}
+
 func Values(printer *Printer, decls ...ast.Node) map[string]*Value {
 	var res = make(map[string]*Value)
+	for _, val := range ValuesList(printer, decls...) {
+		res[val.Name] = val
+	}
+	return res
+}
+
+// ValuesList returns every declared constant and variable in declaration order.
+// Constants without type and value repeat them from previous spec of the same block.
+func ValuesList(printer *Printer, decls ...ast.Node) []*Value {
+	var res []*Value
 	var stack []ast.Node
-	//var name string
 	for i := len(decls) - 1; i >= 0; i-- {
 		stack = append(stack, decls[i])
 	}
@@ -605,44 +872,108 @@ func Values(printer *Printer, decls ...ast.Node) map[string]*Value {
 
 		switch v := node.(type) {
 		case *ast.ValueSpec:
-			val := &Value{}
-			val.Name = v.Names[0].Name
-			val.Type = v.Type
-			if len(v.Values) > 0 {
-				val.Value = v.Values[0] // TODO: check for bounds
-			}
-			val.printer = printer
-			val.Comment = lastComment
-			res[val.Name] = val
+			res = append(res, specValues(printer, nil, v, lastComment)...)
 		case *ast.GenDecl:
 			lastComment = joinComments(printer.CommentMap[v])
-			for _, spec := range v.Specs {
-				stack = append(stack, spec)
+			switch v.Tok {
+			case token.CONST:
+				for _, entry := range constEntries(printer, v) {
+					res = append(res, &Value{
+						Name:        entry.Name.Name,
+						Type:        entry.Type,
+						Value:       entry.Value,
+						Kind:        Const,
+						Comment:     lastComment,
+						Doc:         specDoc(v, entry.Spec.Doc).Text(),
+						LineComment: entry.Spec.Comment.Text(),
+						Annotations: printer.annotations(specDoc(v, entry.Spec.Doc), entry.Spec.Comment),
+						Position:    printer.position(entry.Name, entry.Spec),
+						printer:     printer,
+					})
+				}
+			case token.VAR:
+				for _, spec := range v.Specs {
+					for _, val := range specValues(printer, v, spec.(*ast.ValueSpec), lastComment) {
+						val.Kind = Var
+						res = append(res, val)
+					}
+				}
 			}
 		}
 	}
 	return res
 }
 
+// specValues maps names of spec to values. Multi-value expression (var a, b = f()) is shared by all names
+func specValues(printer *Printer, decl *ast.GenDecl, spec *ast.ValueSpec, comment string) []*Value {
+	var res []*Value
+	for i, name := range spec.Names {
+		val := &Value{
+			Name:        name.Name,
+			Type:        spec.Type,
+			Comment:     comment,
+			Doc:         specDoc(decl, spec.Doc).Text(),
+			LineComment: spec.Comment.Text(),
+			Annotations: printer.annotations(specDoc(decl, spec.Doc), spec.Comment),
+			Position:    printer.position(name, spec),
+			printer:     printer,
+		}
+		if len(spec.Values) == len(spec.Names) {
+			val.Value = spec.Values[i]
+		} else if len(spec.Values) == 1 {
+			val.Value = spec.Values[0]
+		}
+		res = append(res, val)
+	}
+	return res
+}
+
 func AsMethod(m *ast.Field, printer *Printer) *Method {
-	var name string
-	name = m.Names[0].Name
-	method := &Method{Name: name, Comment: joinComments(printer.CommentMap[m])}
-	def := m.Type.(*ast.FuncType)
+	method := asMethod(m.Names[0], joinComments(printer.CommentMap[m]), m.Type.(*ast.FuncType), printer)
+	method.Doc = m.Doc.Text()
+	method.LineComment = m.Comment.Text()
+	method.Annotations = printer.annotations(m.Doc, m.Comment)
+	method.Position = printer.position(m.Names[0], m)
+	return method
+}
+
+func asMethod(ident *ast.Ident, comment string, def *ast.FuncType, printer *Printer) *Method {
+	method := &Method{Comment: comment, ident: ident, printer: printer}
+	if ident != nil {
+		method.Name = ident.Name
+	}
 	if def.Params != nil {
 		method.In = getArgs(printer, def.Params.List)
 	}
 	if def.Results != nil {
 		for i, p := range def.Results.List {
-			name := fmt.Sprintf("ret%v", i)
-			if p.Names != nil {
-				name = p.Names[0].Name
-			}
 			tag := ""
 			if p.Tag != nil {
 				tag = p.Tag.Value
 			}
-			method.Out = append(method.Out, &Arg{name, p.Type, tag, joinComments(printer.CommentMap[p]), printer, p})
+			// grouped results (a, b int) are separate arguments, unnamed result has one generated name
+			idents := p.Names
+			if idents == nil {
+				idents = []*ast.Ident{nil}
+			}
+			for _, ident := range idents {
+				name := fmt.Sprintf("ret%v", i)
+				if ident != nil {
+					name = ident.Name
+				}
+				method.Out = append(method.Out, &Arg{
+					Name:        name,
+					Type:        p.Type,
+					Tag:         tag,
+					Comment:     joinComments(printer.CommentMap[p]),
+					Doc:         p.Doc.Text(),
+					LineComment: p.Comment.Text(),
+					Annotations: printer.annotations(p.Doc, p.Comment),
+					Position:    printer.position(ident, p),
+					printer:     printer,
+					field:       p,
+				})
+			}
 		}
 	}
 	return method
@@ -657,15 +988,64 @@ func getArgs(printer *Printer, fields []*ast.Field) []*Arg {
 				if p.Tag != nil {
 					tag = p.Tag.Value
 				}
-				ans = append(ans, &Arg{name.Name, p.Type, tag, joinComments(printer.CommentMap[p]), printer, p})
+				ans = append(ans, &Arg{
+					Name:        name.Name,
+					Type:        p.Type,
+					Tag:         tag,
+					Comment:     joinComments(printer.CommentMap[p]),
+					Doc:         p.Doc.Text(),
+					LineComment: p.Comment.Text(),
+					Annotations: printer.annotations(p.Doc, p.Comment),
+					Position:    printer.position(name, p),
+					printer:     printer,
+					field:       p,
+				})
 			}
 		} else {
-			ans = append(ans, &Arg{fmt.Sprintf("arg%v", i), p.Type, "", joinComments(printer.CommentMap[p]), printer, p})
+			ans = append(ans, &Arg{
+				Name:        fmt.Sprintf("arg%v", i),
+				Type:        p.Type,
+				Comment:     joinComments(printer.CommentMap[p]),
+				Doc:         p.Doc.Text(),
+				LineComment: p.Comment.Text(),
+				Annotations: printer.annotations(p.Doc, p.Comment),
+				Position:    printer.position(nil, p),
+				printer:     printer,
+				field:       p,
+			})
+		}
+	}
+	return ans
+}
+
+// getFields is same as getArgs but for struct fields: embedded fields are named by type and marked
+func getFields(printer *Printer, fields []*ast.Field) []*Arg {
+	ans := getArgs(printer, fields)
+	var i int
+	for _, p := range fields {
+		if p.Names != nil {
+			i += len(p.Names)
+			continue
+		}
+		arg := ans[i]
+		arg.Name = embeddedName(p.Type)
+		arg.IsEmbedded = true
+		if p.Tag != nil {
+			arg.Tag = p.Tag.Value
 		}
+		i++
 	}
 	return ans
 }
 
+// specDoc returns doc comment of spec. Spec of declaration without parentheses is documented by the declaration
+func specDoc(decl *ast.GenDecl, doc *ast.CommentGroup) *ast.CommentGroup {
+	if doc == nil && decl != nil && !decl.Lparen.IsValid() {
+		return decl.Doc
+	}
+	return doc
+}
+
 func joinComments(comments []*ast.CommentGroup) string {
 	var ans string
 	for _, c := range comments {
@@ -74,7 +74,12 @@ func TestFile_ExtractType(t *testing.T) {
 	t.Log(ex.printer.ToString(ex.Definition))
 	assert.Equal(t, "github.com/shopspring/decimal", ex.File.Import)
 	root, _ := filepath.Abs(".")
-	assert.Equal(t, filepath.Join(root, "vendor/github.com/shopspring/decimal/decimal.go"), ex.File.location)
+	location := filepath.Join(root, "vendor/github.com/shopspring/decimal/decimal.go")
+	if _, err := os.Stat(filepath.Join(root, "vendor")); os.IsNotExist(err) {
+		// without vendoring the package is taken from the module cache
+		location = filepath.Join(ModCacheDir(), "github.com/shopspring/decimal@v0.0.0-20180709203117-cd690d0c9e24/decimal.go")
+	}
+	assert.Equal(t, location, ex.File.location)
 
 	tp = f.Interface("Fs").Method("Call").In[1]
 	ex, err = f.ExtractTypeString(tp.GolangType())
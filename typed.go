@@ -0,0 +1,349 @@
+package atool
+
+import (
+	"github.com/pkg/errors"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"go/types"
+	"path/filepath"
+	"strings"
+)
+
+// typedImporter type-checks imported packages from sources found by resolver of config.
+// Packages are shared by cache of config (if set)
+type typedImporter struct {
+	cfg      *Config
+	tokens   *token.FileSet
+	packages map[string]*types.Package // by directory, nil means import in progress
+	cached   map[*types.Package]*typedPackage
+	fresh    map[*typedPackage]bool // validated entries of cache
+}
+
+func newTypedImporter(cfg *Config, tokens *token.FileSet) *typedImporter {
+	if cfg.Cache != nil {
+		tokens = cfg.Cache.fileSet()
+	}
+	return &typedImporter{
+		cfg:      cfg,
+		tokens:   tokens,
+		packages: make(map[string]*types.Package),
+		cached:   make(map[*types.Package]*typedPackage),
+		fresh:    make(map[*typedPackage]bool),
+	}
+}
+
+func (imp *typedImporter) Import(path string) (*types.Package, error) {
+	return imp.ImportFrom(path, "", 0)
+}
+
+func (imp *typedImporter) ImportFrom(path, dir string, mode types.ImportMode) (*types.Package, error) {
+	if path == "unsafe" {
+		return types.Unsafe, nil
+	}
+	pkgDir, err := imp.cfg.resolver().ResolveImport(dir, path)
+	if err != nil {
+		return nil, errors.Wrapf(err, "resolve %v", path)
+	}
+	if pkgDir == "" {
+		return nil, errors.Errorf("package %v not found", path)
+	}
+	if pkg, ok := imp.packages[pkgDir]; ok {
+		if pkg == nil {
+			return nil, errors.Errorf("import cycle via %v", path)
+		}
+		return pkg, nil
+	}
+	imp.packages[pkgDir] = nil
+	var pkg *types.Package
+	if imp.cfg.Cache != nil {
+		pkg, err = imp.cfg.Cache.importTyped(imp, path, pkgDir)
+	} else {
+		pkg, err = imp.checkDir(path, pkgDir)
+	}
+	if err != nil {
+		delete(imp.packages, pkgDir)
+		return nil, err
+	}
+	imp.packages[pkgDir] = pkg
+	return pkg, nil
+}
+
+func (imp *typedImporter) checkDir(path, dir string) (*types.Package, error) {
+	files, err := imp.parseDir(dir)
+	if err != nil {
+		return nil, err
+	}
+	// errors of dependencies are not interesting: partially checked package is enough for declarations
+	conf := imp.cfg.typesConfig(imp, func(error) {})
+	pkg, _ := conf.Check(path, imp.tokens, files, nil)
+	return pkg, nil
+}
+
+// use registers cached package with dependencies as imported, so the next imports refer to the same packages
+func (imp *typedImporter) use(entry *typedPackage) {
+	if _, ok := imp.cached[entry.pkg]; ok {
+		return
+	}
+	imp.cached[entry.pkg] = entry
+	if imp.packages[entry.dir] == nil {
+		imp.packages[entry.dir] = entry.pkg
+	}
+	for _, dep := range entry.deps {
+		imp.use(dep)
+	}
+}
+
+// parseDir parses declarations of non-test files of the directory without comments and function bodies
+func (imp *typedImporter) parseDir(dir string) ([]*ast.File, error) {
+	content, err := imp.cfg.readDir(dir)
+	if err != nil {
+		return nil, err
+	}
+	ctx := imp.cfg.build()
+	ctx.CgoEnabled = false // dependencies are checked without cgo preprocessing, pure Go variants are used
+	var res []*ast.File
+	for _, info := range content {
+		name := info.Name()
+		if info.IsDir() || !strings.HasSuffix(name, ".go") || strings.HasSuffix(name, "_test.go") {
+			continue
+		}
+		if ok, err := ctx.MatchFile(dir, name); err != nil || !ok {
+			continue
+		}
+		filename := filepath.Join(dir, name)
+		source, err := imp.cfg.readFile(filename)
+		if err != nil {
+			return nil, err
+		}
+		file, err := parser.ParseFile(imp.tokens, filename, source, parser.SkipObjectResolution)
+		if err != nil {
+			return nil, errors.Wrapf(err, "parse %v", name)
+		}
+		for _, decl := range file.Decls {
+			if fn, ok := decl.(*ast.FuncDecl); ok {
+				fn.Body = nil
+			}
+		}
+		res = append(res, file)
+	}
+	return res, nil
+}
+
+func (cfg *Config) typesConfig(imp types.Importer, handler func(error)) *types.Config {
+	return &types.Config{
+		Importer:    imp,
+		Error:       handler,
+		FakeImportC: true,
+		Sizes:       types.SizesFor("gc", cfg.build().GOARCH),
+	}
+}
+
+// check type-checks files of one package and saves type information to the files
+func (cfg *Config) check(tokens *token.FileSet, files []*File) (*types.Package, *types.Info, error) {
+	var syntax []*ast.File
+	for _, file := range files {
+		syntax = append(syntax, file.syntax)
+	}
+	info := &types.Info{
+		Types:      make(map[ast.Expr]types.TypeAndValue),
+		Defs:       make(map[*ast.Ident]types.Object),
+		Uses:       make(map[*ast.Ident]types.Object),
+		Implicits:  make(map[ast.Node]types.Object),
+		Selections: make(map[*ast.SelectorExpr]*types.Selection),
+	}
+	var diagnostics []*Diagnostic
+	conf := cfg.typesConfig(newTypedImporter(cfg, tokens), func(err error) {
+		diagnostics = append(diagnostics, typeDiagnostic(err))
+	})
+	path := files[0].Import
+	if path == "" {
+		path = importPath(filepath.Dir(files[0].location), files[0].Package)
+	}
+	pkg, _ := conf.Check(path, tokens, syntax, info)
+	if len(diagnostics) > 0 && !cfg.Partial {
+		return nil, nil, errors.New("type check: " + diagnostics[0].String())
+	}
+	for _, file := range files {
+		file.TypesPackage = pkg
+		file.TypesInfo = info
+		for _, diagnostic := range diagnostics {
+			if diagnostic.Position.Filename == file.location {
+				file.Diagnostics = append(file.Diagnostics, diagnostic)
+			}
+		}
+	}
+	return pkg, info, nil
+}
+
+// checkFile type-checks the file together with other files of the same package in the directory
+func (cfg *Config) checkFile(tokens *token.FileSet, file *File) error {
+	near, err := cfg.scanDir(tokens, filepath.Dir(file.location))
+	if err != nil {
+		return err
+	}
+	files := []*File{file}
+	for i, childFile := range near {
+		if childFile.location == file.location {
+			near[i] = file
+			continue
+		}
+		childFile.Import = file.Import
+		childFile.near = near
+		if childFile.Package == file.Package {
+			files = append(files, childFile)
+		}
+	}
+	file.near = near
+	attachPackageMethods(near, file)
+	_, _, err = cfg.check(tokens, files)
+	return err
+}
+
+// importPath detects import path of the directory by module. Returns fallback if directory is not in a module
+func importPath(dir, fallback string) string {
+	mod, err := FindModule(dir)
+	if err != nil || mod == nil {
+		return fallback
+	}
+	abs, err := filepath.Abs(dir)
+	if err != nil {
+		return fallback
+	}
+	rel, err := filepath.Rel(mod.Dir, abs)
+	if err != nil || strings.HasPrefix(rel, "..") {
+		return fallback
+	}
+	if rel == "." {
+		return mod.Path
+	}
+	return mod.Path + "/" + filepath.ToSlash(rel)
+}
+
+// typesInfo returns type information of source or nil if it is not type-checked
+func (p *Printer) typesInfo() *types.Info {
+	if p == nil || p.source == nil {
+		return nil
+	}
+	return p.source.TypesInfo
+}
+
+// object of top-level declaration in type-checked file
+func (f *File) object(name string) types.Object {
+	if f == nil || f.TypesPackage == nil {
+		return nil
+	}
+	return f.TypesPackage.Scope().Lookup(name)
+}
+
+// TypeOf returns checked type of argument or nil if typed mode is not enabled
+func (arg *Arg) TypeOf() types.Type {
+	info := arg.printer.typesInfo()
+	if info == nil {
+		return nil
+	}
+	return info.TypeOf(arg.Type)
+}
+
+// Underlying returns checked underlying type of argument or nil
+func (arg *Arg) Underlying() types.Type {
+	if tp := arg.TypeOf(); tp != nil {
+		return tp.Underlying()
+	}
+	return nil
+}
+
+// PkgPath returns import path of package where named type of argument (pointers are skipped) is declared.
+// Returns empty string for predeclared, unnamed and unchecked types
+func (arg *Arg) PkgPath() string {
+	tp := arg.TypeOf()
+	for {
+		ptr, ok := tp.(*types.Pointer)
+		if !ok {
+			break
+		}
+		tp = ptr.Elem()
+	}
+	if named, ok := tp.(*types.Named); ok && named.Obj().Pkg() != nil {
+		return named.Obj().Pkg().Path()
+	}
+	return ""
+}
+
+// IsComparable checks that values of argument type could be compared by == (only in typed mode)
+func (arg *Arg) IsComparable() bool {
+	tp := arg.TypeOf()
+	return tp != nil && types.Comparable(tp)
+}
+
+// AssignableTo checks that value of argument type is assignable to the type (only in typed mode)
+func (arg *Arg) AssignableTo(tp types.Type) bool {
+	own := arg.TypeOf()
+	return own != nil && tp != nil && types.AssignableTo(own, tp)
+}
+
+// Implements checks that argument type implements interface (only in typed mode)
+func (arg *Arg) Implements(iface types.Type) bool {
+	own := arg.TypeOf()
+	return own != nil && implements(own, iface)
+}
+
+// ImplementsError checks that argument type implements error interface (only in typed mode)
+func (arg *Arg) ImplementsError() bool {
+	return arg.Implements(types.Universe.Lookup("error").Type())
+}
+
+// TypeString returns checked type with full package paths (ex: *net/url.URL) or empty string
+func (arg *Arg) TypeString() string {
+	if tp := arg.TypeOf(); tp != nil {
+		return types.TypeString(tp, nil)
+	}
+	return ""
+}
+
+// Object returns checked type name of struct or nil if typed mode is not enabled
+func (s *Struct) Object() *types.TypeName {
+	obj, _ := s.File.object(s.Name).(*types.TypeName)
+	return obj
+}
+
+// TypeOf returns checked named type of struct or nil
+func (s *Struct) TypeOf() types.Type {
+	if obj := s.Object(); obj != nil {
+		return obj.Type()
+	}
+	return nil
+}
+
+// Implements checks that struct or pointer to struct implements interface (only in typed mode)
+func (s *Struct) Implements(iface types.Type) bool {
+	tp := s.TypeOf()
+	return tp != nil && (implements(tp, iface) || implements(types.NewPointer(tp), iface))
+}
+
+// Object returns checked function or method or nil if typed mode is not enabled
+func (m *Method) Object() *types.Func {
+	info := m.printer.typesInfo()
+	if info == nil || m.ident == nil {
+		return nil
+	}
+	fn, _ := info.Defs[m.ident].(*types.Func)
+	return fn
+}
+
+// Signature returns checked signature or nil
+func (m *Method) Signature() *types.Signature {
+	if fn := m.Object(); fn != nil {
+		sig, _ := fn.Type().(*types.Signature)
+		return sig
+	}
+	return nil
+}
+
+func implements(tp, iface types.Type) bool {
+	if iface == nil {
+		return false
+	}
+	v, ok := iface.Underlying().(*types.Interface)
+	return ok && types.Implements(tp, v)
+}
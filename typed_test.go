@@ -0,0 +1,51 @@
+package atool
+
+import (
+	"github.com/alecthomas/assert"
+	"go/types"
+	"testing"
+)
+
+func TestConfig_Typed(t *testing.T) {
+	cfg := &Config{Typed: true}
+	pkg, err := cfg.ScanPackage("test/typed")
+	assert.Nil(t, err)
+	assert.NotNil(t, pkg.TypesPackage)
+	assert.Equal(t, "github.com/reddec/astools/test/typed", pkg.TypesPackage.Path())
+
+	account := pkg.Struct("Account")
+	assert.Equal(t, "time", account.Field("Created").PkgPath())
+	assert.Equal(t, "net/url", account.Field("Link").PkgPath())
+	assert.Equal(t, "*net/url.URL", account.Field("Link").TypeString())
+	assert.Equal(t, "", account.Field("Tags").PkgPath())
+	assert.True(t, account.Field("Created").IsComparable())
+	assert.False(t, account.Field("Limits").IsComparable())
+	assert.True(t, account.Field("Reason").ImplementsError())
+	assert.False(t, account.Field("Name").ImplementsError())
+	_, ok := account.Field("Reason").Underlying().(*types.Basic)
+	assert.True(t, ok)
+	assert.True(t, account.Field("Name").AssignableTo(types.Typ[types.String]))
+
+	stringer := types.NewInterfaceType([]*types.Func{
+		types.NewFunc(0, nil, "String", types.NewSignatureType(nil, nil, nil, nil,
+			types.NewTuple(types.NewVar(0, nil, "", types.Typ[types.String])), false)),
+	}, nil).Complete()
+	assert.True(t, account.Implements(stringer))
+	assert.NotNil(t, account.TypeOf())
+
+	open := pkg.Func("Open")
+	assert.NotNil(t, open.Signature())
+	assert.Equal(t, 2, open.Signature().Params().Len())
+	assert.True(t, open.Out[1].ImplementsError())
+
+	// single file is checked with siblings
+	f, err := cfg.Scan("test/typed/typed.go")
+	assert.Nil(t, err)
+	assert.Equal(t, "net/url", f.Struct("Account").Field("Link").PkgPath())
+
+	// syntactic mode has no type information
+	pkg, err = ScanPackage("test/typed")
+	assert.Nil(t, err)
+	assert.Nil(t, pkg.Struct("Account").Field("Link").TypeOf())
+	assert.Equal(t, "", pkg.Struct("Account").Field("Link").PkgPath())
+}
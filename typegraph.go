@@ -0,0 +1,222 @@
+package atool
+
+import (
+	"path/filepath"
+)
+
+// TypeNode is a declared type of graph with types used by it directly
+type TypeNode struct {
+	Name    string
+	Package string      // package name
+	Import  string      `json:",omitempty"` // import path, empty for scanned (not imported) package
+	Cyclic  bool        `json:",omitempty"` // type is a part of dependency cycle (including self-reference)
+	Type    Named       `json:"-"`
+	Deps    []*TypeNode `json:"-"` // in order of appearance in declaration
+}
+
+// QualifiedName returns name with package name (ex: model.User)
+func (n *TypeNode) QualifiedName() string {
+	return n.Package + "." + n.Name
+}
+
+// TypeGraph is a set of declared types reachable from roots: types of struct fields, parameters and results of
+// interface methods, embedded types, underlying types of named types and type arguments - recursively, across packages.
+// Methods of structs and constraints of type parameters are not followed. Predeclared types are not included
+type TypeGraph struct {
+	Nodes      []*TypeNode   // topological order: dependencies before types which use them, roots order is kept
+	Cycles     [][]*TypeNode // groups of types which depend on each other
+	Unresolved []string      `json:",omitempty"` // names of types which can't be found (ex: package not resolved)
+	nodes      map[string]*TypeNode
+}
+
+// NewTypeGraph collects all types reachable from roots
+func NewTypeGraph(roots ...Named) *TypeGraph {
+	graph := &TypeGraph{nodes: make(map[string]*TypeNode)}
+	var start []*TypeNode
+	for _, root := range roots {
+		start = append(start, graph.discover(root))
+	}
+	sorter := &graphSorter{graph: graph, index: make(map[*TypeNode]int), low: make(map[*TypeNode]int), onStack: make(map[*TypeNode]bool)}
+	for _, node := range start {
+		if _, visited := sorter.index[node]; !visited {
+			sorter.connect(node)
+		}
+	}
+	return graph
+}
+
+// Node returns type by name, optionally qualified by package name (ex: model.User)
+func (g *TypeGraph) Node(name string) *TypeNode {
+	for _, node := range g.Nodes {
+		if node.Name == name || node.QualifiedName() == name {
+			return node
+		}
+	}
+	return nil
+}
+
+// Structs returns structs of graph in topological order
+func (g *TypeGraph) Structs() []*Struct {
+	var res []*Struct
+	for _, node := range g.Nodes {
+		if v, ok := node.Type.(*Struct); ok {
+			res = append(res, v)
+		}
+	}
+	return res
+}
+
+// discover registers node of type and, recursively, nodes of its dependencies
+func (g *TypeGraph) discover(tp Named) *TypeNode {
+	key := typeKey(tp)
+	if node, ok := g.nodes[key]; ok {
+		return node
+	}
+	node := &TypeNode{Name: tp.TypeName(), Type: tp}
+	g.nodes[key] = node
+	src := tp.Source()
+	if src == nil {
+		return node
+	}
+	node.Package, node.Import = src.Package, src.Import
+
+	refs, params := typeReferences(tp)
+	scope := make(map[string]bool)
+	for _, param := range params {
+		scope[param.Name] = true
+	}
+	seen := make(map[*TypeNode]bool)
+	for _, ref := range refs {
+		ref.walk(func(item *TypeRef) {
+			if !item.IsNamed() {
+				return
+			}
+			if _, predeclared := predeclaredKinds[item.Name]; item.Kind == RefIdent && (predeclared || scope[item.Name]) {
+				return
+			}
+			name := item.QualifiedName()
+			if name == "unsafe.Pointer" {
+				return
+			}
+			named, err := src.ExtractNamedString(name)
+			if err != nil || named == nil {
+				g.unresolved(name)
+				return
+			}
+			dep := g.discover(named)
+			if !seen[dep] {
+				seen[dep] = true
+				node.Deps = append(node.Deps, dep)
+			}
+		})
+	}
+	return node
+}
+
+func (g *TypeGraph) unresolved(name string) {
+	for _, v := range g.Unresolved {
+		if v == name {
+			return
+		}
+	}
+	g.Unresolved = append(g.Unresolved, name)
+}
+
+// graphSorter orders nodes by strongly connected components (Tarjan's algorithm): component is emitted after
+// all components reachable from it
+type graphSorter struct {
+	graph   *TypeGraph
+	index   map[*TypeNode]int
+	low     map[*TypeNode]int
+	stack   []*TypeNode
+	onStack map[*TypeNode]bool
+}
+
+func (s *graphSorter) connect(node *TypeNode) {
+	s.index[node] = len(s.index)
+	s.low[node] = s.index[node]
+	s.stack = append(s.stack, node)
+	s.onStack[node] = true
+	selfRef := false
+	for _, dep := range node.Deps {
+		if _, visited := s.index[dep]; !visited {
+			s.connect(dep)
+			s.low[node] = minInt(s.low[node], s.low[dep])
+		} else if s.onStack[dep] {
+			s.low[node] = minInt(s.low[node], s.index[dep])
+		}
+		selfRef = selfRef || dep == node
+	}
+	if s.low[node] != s.index[node] {
+		return
+	}
+	// members of component are above the node in stack, in order of discovery
+	var pos = len(s.stack) - 1
+	for s.stack[pos] != node {
+		pos--
+	}
+	component := append([]*TypeNode(nil), s.stack[pos:]...)
+	s.stack = s.stack[:pos]
+	for _, member := range component {
+		s.onStack[member] = false
+	}
+	if len(component) > 1 || selfRef {
+		for _, member := range component {
+			member.Cyclic = true
+		}
+		s.graph.Cycles = append(s.graph.Cycles, component)
+	}
+	s.graph.Nodes = append(s.graph.Nodes, component...)
+}
+
+// typeReferences returns type expressions used by declaration and its type parameters
+func typeReferences(tp Named) ([]*TypeRef, []*Arg) {
+	var refs []*TypeRef
+	switch v := tp.(type) {
+	case *Struct:
+		for _, field := range v.Fields {
+			refs = append(refs, field.Ref())
+		}
+		return refs, v.TypeParams
+	case *Interface:
+		for _, embedded := range v.Embedded {
+			refs = append(refs, embedded.Ref())
+		}
+		for _, union := range v.Unions {
+			for _, term := range union {
+				refs = append(refs, term.Ref())
+			}
+		}
+		for _, method := range v.Methods {
+			for _, args := range [][]*Arg{method.In, method.Out} {
+				for _, arg := range args {
+					refs = append(refs, arg.Ref())
+				}
+			}
+		}
+		return refs, v.TypeParams
+	case *NamedType:
+		return []*TypeRef{v.Ref()}, v.TypeParams
+	}
+	return nil, nil
+}
+
+// typeKey identifies declaration by directory of package and name
+func typeKey(tp Named) string {
+	src := tp.Source()
+	if src == nil {
+		return tp.TypeName()
+	}
+	dir := filepath.Dir(src.Location())
+	if abs, err := filepath.Abs(dir); err == nil {
+		dir = abs
+	}
+	return dir + "." + tp.TypeName()
+}
+
+func minInt(a, b int) int {
+	if a < b {
+		return a
+	}
+	return b
+}
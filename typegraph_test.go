@@ -0,0 +1,42 @@
+package atool
+
+import (
+	"github.com/alecthomas/assert"
+	"testing"
+)
+
+func nodeNames(nodes []*TypeNode) []string {
+	var res []string
+	for _, node := range nodes {
+		res = append(res, node.QualifiedName())
+	}
+	return res
+}
+
+func TestNewTypeGraph(t *testing.T) {
+	f, err := Scan("test/testdata/graph/graph.go")
+	assert.Nil(t, err)
+
+	graph := NewTypeGraph(f.Interface("Service"))
+	assert.Equal(t, []string{
+		"url.Userinfo", "url.URL", "graph.Node", "graph.Cursor", "graph.Page", "model.ID", "model.User", "model.Group",
+		"graph.CreateRequest", "graph.Tag", "graph.Tags", "graph.Filter", "graph.Service",
+	}, nodeNames(graph.Nodes))
+	assert.Len(t, graph.Cycles, 2)
+	assert.Equal(t, []string{"graph.Node"}, nodeNames(graph.Cycles[0]))
+	assert.Equal(t, []string{"model.User", "model.Group"}, nodeNames(graph.Cycles[1]))
+	assert.Equal(t, []string{"missing.Extra"}, graph.Unresolved)
+
+	user := graph.Node("model.User")
+	assert.True(t, user.Cyclic)
+	assert.Equal(t, "github.com/reddec/astools/test/testdata/graph/model", user.Import)
+	assert.Equal(t, []string{"model.ID", "model.User", "model.Group"}, nodeNames(user.Deps))
+	assert.False(t, graph.Node("Filter").Cyclic)
+	assert.Equal(t, []string{"graph.Cursor"}, nodeNames(graph.Node("Page").Deps))
+	assert.Len(t, graph.Structs(), 9)
+
+	// roots order is kept
+	graph = NewTypeGraph(f.Struct("Tag"), f.Struct("Node"))
+	assert.Equal(t, []string{"graph.Tag", "graph.Node"}, nodeNames(graph.Nodes))
+	assert.Len(t, graph.Unresolved, 0)
+}
@@ -0,0 +1,283 @@
+package atool
+
+import (
+	"go/ast"
+	"strings"
+)
+
+// RefKind is a kind of type expression
+type RefKind string
+
+const (
+	RefIdent     RefKind = "ident"     // local or predeclared type (ex: int, User)
+	RefQualified RefKind = "qualified" // type of imported package (ex: time.Time)
+	RefPointer   RefKind = "pointer"
+	RefSlice     RefKind = "slice"
+	RefArray     RefKind = "array"
+	RefEllipsis  RefKind = "ellipsis" // variadic parameter (ex: ...string)
+	RefMap       RefKind = "map"
+	RefChan      RefKind = "chan"
+	RefFunc      RefKind = "func"
+	RefStruct    RefKind = "struct"
+	RefInterface RefKind = "interface"
+	RefGeneric   RefKind = "generic" // instantiation of generic type (ex: List[int])
+	RefOther     RefKind = "other"   // any other expression (ex: union of constraint), kept as source in Name
+)
+
+// ChanDir is a direction of channel
+type ChanDir string
+
+const (
+	ChanBoth ChanDir = "both"
+	ChanSend ChanDir = "send"
+	ChanRecv ChanDir = "recv"
+)
+
+// TypeRef is a tree of type expression
+type TypeRef struct {
+	Kind     RefKind
+	Name     string     `json:",omitempty"` // type name for ident and qualified
+	Package  string     `json:",omitempty"` // package alias for qualified
+	Len      string     `json:",omitempty"` // length expression of array (ex: 4, N, ...)
+	Dir      ChanDir    `json:",omitempty"` // direction of chan
+	Key      *TypeRef   `json:",omitempty"` // key of map
+	Elem     *TypeRef   `json:",omitempty"` // element of pointer, slice, array, ellipsis, map, chan or generic type of instantiation
+	TypeArgs []*TypeRef `json:",omitempty"` // type arguments of instantiation
+	In       []*Arg     `json:",omitempty"` // parameters of func
+	Out      []*Arg     `json:",omitempty"` // results of func
+	Fields   []*Arg     `json:",omitempty"` // fields of inline struct
+	Methods  []*Method  `json:",omitempty"` // methods of inline interface
+	Embedded []*TypeRef `json:",omitempty"` // embedded types and type elements of inline interface
+	Expr     ast.Expr   `json:"-"`
+}
+
+// AsTypeRef builds tree of type expression. Returns nil for nil expression
+func AsTypeRef(expr ast.Expr, printer *Printer) *TypeRef {
+	if expr == nil {
+		return nil
+	}
+	ref := &TypeRef{Expr: expr}
+	switch v := expr.(type) {
+	case *ast.ParenExpr:
+		return AsTypeRef(v.X, printer)
+	case *ast.Ident:
+		ref.Kind = RefIdent
+		ref.Name = v.Name
+	case *ast.SelectorExpr:
+		ref.Kind = RefQualified
+		ref.Name = v.Sel.Name
+		ref.Package = printer.ToString(v.X)
+	case *ast.StarExpr:
+		ref.Kind = RefPointer
+		ref.Elem = AsTypeRef(v.X, printer)
+	case *ast.ArrayType:
+		ref.Kind = RefSlice
+		if v.Len != nil {
+			ref.Kind = RefArray
+			ref.Len = printer.ToString(v.Len)
+		}
+		ref.Elem = AsTypeRef(v.Elt, printer)
+	case *ast.Ellipsis:
+		ref.Kind = RefEllipsis
+		ref.Elem = AsTypeRef(v.Elt, printer)
+	case *ast.MapType:
+		ref.Kind = RefMap
+		ref.Key = AsTypeRef(v.Key, printer)
+		ref.Elem = AsTypeRef(v.Value, printer)
+	case *ast.ChanType:
+		ref.Kind = RefChan
+		switch v.Dir {
+		case ast.SEND:
+			ref.Dir = ChanSend
+		case ast.RECV:
+			ref.Dir = ChanRecv
+		default:
+			ref.Dir = ChanBoth
+		}
+		ref.Elem = AsTypeRef(v.Value, printer)
+	case *ast.FuncType:
+		ref.Kind = RefFunc
+		method := asMethod(nil, "", v, printer)
+		ref.In, ref.Out = method.In, method.Out
+	case *ast.StructType:
+		ref.Kind = RefStruct
+		ref.Fields = getFields(printer, v.Fields.List)
+	case *ast.InterfaceType:
+		ref.Kind = RefInterface
+		for _, m := range v.Methods.List {
+			if len(m.Names) == 0 {
+				ref.Embedded = append(ref.Embedded, AsTypeRef(m.Type, printer))
+				continue
+			}
+			ref.Methods = append(ref.Methods, AsMethod(m, printer))
+		}
+	case *ast.IndexExpr:
+		ref.Kind = RefGeneric
+		ref.Elem = AsTypeRef(v.X, printer)
+		ref.TypeArgs = []*TypeRef{AsTypeRef(v.Index, printer)}
+	case *ast.IndexListExpr:
+		ref.Kind = RefGeneric
+		ref.Elem = AsTypeRef(v.X, printer)
+		for _, index := range v.Indices {
+			ref.TypeArgs = append(ref.TypeArgs, AsTypeRef(index, printer))
+		}
+	default:
+		ref.Kind = RefOther
+		ref.Name = printer.ToString(expr)
+	}
+	return ref
+}
+
+// IsNamed checks that type is referenced by name (local, predeclared or qualified)
+func (ref *TypeRef) IsNamed() bool {
+	return ref.Kind == RefIdent || ref.Kind == RefQualified
+}
+
+// Deref returns type without pointers
+func (ref *TypeRef) Deref() *TypeRef {
+	for ref.Kind == RefPointer {
+		ref = ref.Elem
+	}
+	return ref
+}
+
+// QualifiedName returns name with package alias (ex: time.Time) for named types, otherwise rendered type
+func (ref *TypeRef) QualifiedName() string {
+	if ref.Kind == RefQualified {
+		return ref.Package + "." + ref.Name
+	}
+	if ref.Kind == RefIdent || ref.Kind == RefOther {
+		return ref.Name
+	}
+	return ref.String()
+}
+
+// Packages returns aliases of all packages referred by type in order of appearance
+func (ref *TypeRef) Packages() []string {
+	var res []string
+	seen := make(map[string]bool)
+	ref.walk(func(item *TypeRef) {
+		if item.Kind == RefQualified && !seen[item.Package] {
+			seen[item.Package] = true
+			res = append(res, item.Package)
+		}
+	})
+	return res
+}
+
+func (ref *TypeRef) walk(visit func(item *TypeRef)) {
+	if ref == nil {
+		return
+	}
+	visit(ref)
+	ref.Key.walk(visit)
+	ref.Elem.walk(visit)
+	for _, item := range ref.TypeArgs {
+		item.walk(visit)
+	}
+	for _, item := range ref.Embedded {
+		item.walk(visit)
+	}
+	for _, args := range [][]*Arg{ref.In, ref.Out, ref.Fields} {
+		for _, arg := range args {
+			arg.Ref().walk(visit)
+		}
+	}
+	for _, m := range ref.Methods {
+		for _, args := range [][]*Arg{m.In, m.Out} {
+			for _, arg := range args {
+				arg.Ref().walk(visit)
+			}
+		}
+	}
+}
+
+// String renders type in Go syntax. Inline structs and interfaces are rendered in one line
+func (ref *TypeRef) String() string {
+	if ref == nil {
+		return ""
+	}
+	switch ref.Kind {
+	case RefPointer:
+		return "*" + ref.Elem.String()
+	case RefSlice:
+		return "[]" + ref.Elem.String()
+	case RefArray:
+		return "[" + ref.Len + "]" + ref.Elem.String()
+	case RefEllipsis:
+		return "..." + ref.Elem.String()
+	case RefMap:
+		return "map[" + ref.Key.String() + "]" + ref.Elem.String()
+	case RefChan:
+		switch ref.Dir {
+		case ChanSend:
+			return "chan<- " + ref.Elem.String()
+		case ChanRecv:
+			return "<-chan " + ref.Elem.String()
+		}
+		if ref.Elem.Kind == RefChan && ref.Elem.Dir == ChanRecv {
+			return "chan (" + ref.Elem.String() + ")"
+		}
+		return "chan " + ref.Elem.String()
+	case RefFunc:
+		return "func" + signatureString(ref.In, ref.Out)
+	case RefStruct:
+		var fields []string
+		for _, field := range ref.Fields {
+			item := field.Ref().String()
+			if !field.IsEmbedded {
+				item = field.Name + " " + item
+			}
+			if field.Tag != "" {
+				item += " " + field.Tag
+			}
+			fields = append(fields, item)
+		}
+		return "struct{" + strings.Join(fields, "; ") + "}"
+	case RefInterface:
+		var items []string
+		for _, embedded := range ref.Embedded {
+			items = append(items, embedded.String())
+		}
+		for _, m := range ref.Methods {
+			items = append(items, m.Name+signatureString(m.In, m.Out))
+		}
+		return "interface{" + strings.Join(items, "; ") + "}"
+	case RefGeneric:
+		var args []string
+		for _, arg := range ref.TypeArgs {
+			args = append(args, arg.String())
+		}
+		return ref.Elem.String() + "[" + strings.Join(args, ", ") + "]"
+	}
+	return ref.QualifiedName()
+}
+
+// signatureString renders parameters and results of function. Generated names (arg0, ret0) are omitted
+func signatureString(in, out []*Arg) string {
+	res := "(" + paramsString(in) + ")"
+	switch {
+	case len(out) == 1 && !hasName(out[0]):
+		res += " " + out[0].Ref().String()
+	case len(out) > 0:
+		res += " (" + paramsString(out) + ")"
+	}
+	return res
+}
+
+func paramsString(args []*Arg) string {
+	var params []string
+	for _, arg := range args {
+		if hasName(arg) {
+			params = append(params, arg.Name+" "+arg.Ref().String())
+		} else {
+			params = append(params, arg.Ref().String())
+		}
+	}
+	return strings.Join(params, ", ")
+}
+
+// hasName checks that argument has name in source
+func hasName(arg *Arg) bool {
+	return arg.field == nil || len(arg.field.Names) > 0
+}
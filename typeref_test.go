@@ -0,0 +1,71 @@
+package atool
+
+import (
+	"encoding/json"
+	"github.com/alecthomas/assert"
+	"testing"
+)
+
+func TestArg_Ref(t *testing.T) {
+	f, err := Scan("test/testdata/refs/refs.go")
+	assert.Nil(t, err)
+	refs := f.Struct("Refs")
+	assert.NotNil(t, refs)
+
+	// rendering matches source for every field
+	for _, field := range refs.Fields {
+		if field.Name != "Inline" && field.Name != "Closer" && field.Name != "Split" {
+			assert.Equal(t, field.GolangType(), field.Ref().String(), field.Name)
+		}
+	}
+	assert.Equal(t, "struct{io.Writer; Name string `json:\"name\"`}", refs.Field("Inline").Ref().String())
+	assert.Equal(t, "interface{io.Closer; Flush(force bool) error}", refs.Field("Closer").Ref().String())
+
+	index := refs.Field("Index").Ref()
+	assert.Equal(t, RefMap, index.Kind)
+	assert.Equal(t, "string", index.Key.Name)
+	assert.Equal(t, RefPointer, index.Elem.Kind)
+	assert.Equal(t, RefQualified, index.Elem.Elem.Kind)
+	assert.Equal(t, "url", index.Elem.Elem.Package)
+	assert.Equal(t, "URL", index.Elem.Elem.Name)
+
+	buffer := refs.Field("Buffer").Ref()
+	assert.Equal(t, RefArray, buffer.Kind)
+	assert.Equal(t, "4", buffer.Len)
+
+	handler := refs.Field("Handler").Ref()
+	assert.Equal(t, RefFunc, handler.Kind)
+	assert.Len(t, handler.In, 2)
+	assert.Equal(t, RefEllipsis, handler.In[1].Ref().Kind)
+	assert.Len(t, handler.Out, 2)
+	assert.True(t, handler.Out[1].IsError())
+
+	split := refs.Field("Split").Ref()
+	assert.Len(t, split.Out, 2)
+	assert.Equal(t, "rem", split.Out[1].Name)
+	assert.Equal(t, "func() (quo int, rem int)", split.String())
+
+	assert.Equal(t, ChanSend, refs.Field("Events").Ref().Dir)
+	assert.Equal(t, ChanRecv, refs.Field("Updates").Ref().Dir)
+	assert.Equal(t, ChanBoth, refs.Field("Nested").Ref().Dir)
+
+	pairs := refs.Field("Pairs").Ref()
+	assert.Equal(t, RefGeneric, pairs.Elem.Kind)
+	assert.Equal(t, "Pair", pairs.Elem.Elem.Name)
+	assert.Len(t, pairs.Elem.TypeArgs, 2)
+	assert.Equal(t, []string{"url"}, pairs.Packages())
+
+	pkg, name := refs.Field("Pointers").GoPkgType()
+	assert.Equal(t, "", pkg)
+	assert.Equal(t, "[]*time.Time", name)
+	pkg, name = refs.Field("Location").GoPkgType()
+	assert.Equal(t, "url", pkg)
+	assert.Equal(t, "URL", name)
+
+	assert.Equal(t, "func(ctx map[string]interface{}) (res []byte, err error)", f.Type("Callback").Ref().String())
+	assert.Equal(t, "time.Duration", f.Value("Timeout").Ref().QualifiedName())
+
+	data, err := json.Marshal(refs.Field("Events"))
+	assert.Nil(t, err)
+	assert.Contains(t, string(data), `"Ref":{"Kind":"chan","Dir":"send","Elem":{"Kind":"qualified","Name":"Duration","Package":"time"}}`)
+}
@@ -0,0 +1,181 @@
+package atool
+
+import (
+	"encoding/json"
+	"go/ast"
+)
+
+// NamedType is a declared type that is neither struct nor interface (ex: type Status int) or an alias (type A = B)
+type NamedType struct {
+	Name        string
+	Comment     string // combined view of all comments
+	Doc         string
+	LineComment string
+	Annotations Annotations
+	TypeParams  []*Arg
+	IsAlias     bool
+	Type        ast.Expr // underlying type expression
+	Methods     []*Method
+	Definition  *ast.TypeSpec
+	Position    *Position
+	File        *File
+	printer     *Printer
+}
+
+// GolangType returns source of underlying type (or aliased type)
+func (t *NamedType) GolangType() string {
+	return t.printer.ToString(t.Type)
+}
+
+// Ref returns tree of underlying type (or aliased type)
+func (t *NamedType) Ref() *TypeRef { return AsTypeRef(t.Type, t.printer) }
+
+func (t *NamedType) GoLang() string {
+	if t.IsAlias {
+		return "type " + t.Name + typeParamsString(t.TypeParams) + " = " + t.GolangType()
+	}
+	return "type " + t.Name + typeParamsString(t.TypeParams) + " " + t.GolangType()
+}
+
+func (t *NamedType) Method(name string) *Method {
+	for _, m := range t.Methods {
+		if m.Name == name {
+			return m
+		}
+	}
+	return nil
+}
+
+func (t *NamedType) MarshalJSON() ([]byte, error) {
+	return json.Marshal(&struct {
+		Name        string
+		Comment     string      `json:",omitempty"`
+		Doc         string      `json:",omitempty"`
+		LineComment string      `json:",omitempty"`
+		Annotations Annotations `json:",omitempty"`
+		TypeParams  []*Arg      `json:",omitempty"`
+		IsAlias     bool        `json:",omitempty"`
+		GolangType  string
+		Ref         *TypeRef
+		Methods     []*Method `json:",omitempty"`
+		Position    *Position `json:",omitempty"`
+	}{
+		Name:        t.Name,
+		Comment:     t.Comment,
+		Doc:         t.Doc,
+		LineComment: t.LineComment,
+		Annotations: t.Annotations,
+		TypeParams:  t.TypeParams,
+		IsAlias:     t.IsAlias,
+		GolangType:  t.GolangType(),
+		Ref:         t.Ref(),
+		Methods:     t.Methods,
+		Position:    t.Position,
+	})
+}
+
+// Types returns declared types except structs and interfaces
+func Types(printer *Printer, decls ...ast.Node) []*NamedType {
+	var res []*NamedType
+	var stack []ast.Node
+	for i := len(decls) - 1; i >= 0; i-- {
+		stack = append(stack, decls[i])
+	}
+	var lastComment string
+	var lastDecl *ast.GenDecl
+	for len(stack) > 0 {
+		node := stack[len(stack)-1]
+		stack = stack[:len(stack)-1]
+		switch v := node.(type) {
+		case *ast.TypeSpec:
+			switch v.Type.(type) {
+			case *ast.StructType, *ast.InterfaceType:
+				continue
+			}
+			res = append(res, &NamedType{
+				Name:        v.Name.Name,
+				Comment:     lastComment,
+				Doc:         specDoc(lastDecl, v.Doc).Text(),
+				LineComment: v.Comment.Text(),
+				Annotations: printer.annotations(specDoc(lastDecl, v.Doc), v.Comment),
+				TypeParams:  getTypeParams(printer, v.TypeParams),
+				IsAlias:     v.Assign.IsValid(),
+				Type:        v.Type,
+				Definition:  v,
+				Position:    printer.position(v.Name, v),
+				printer:     printer,
+			})
+		case *ast.GenDecl:
+			lastComment = joinComments(printer.CommentMap[v])
+			lastDecl = v
+			// keep declaration order of grouped specs
+			for i := len(v.Specs) - 1; i >= 0; i-- {
+				stack = append(stack, v.Specs[i])
+			}
+		}
+	}
+	return res
+}
+
+// Named is a declared type: *Struct, *Interface or *NamedType
+type Named interface {
+	// TypeName is a name of declared type
+	TypeName() string
+	// GoLang is a source of declaration
+	GoLang() string
+	// Source file of declaration
+	Source() *File
+}
+
+func (s *Struct) TypeName() string     { return s.Name }
+func (s *Struct) Source() *File        { return s.File }
+func (in *Interface) TypeName() string { return in.Name }
+func (in *Interface) Source() *File    { return in.File }
+func (t *NamedType) TypeName() string  { return t.Name }
+func (t *NamedType) Source() *File     { return t.File }
+
+// Named finds declared type in the file
+func (f *File) Named(name string) Named {
+	if v := f.Struct(name); v != nil {
+		return v
+	}
+	if v := f.Interface(name); v != nil {
+		return v
+	}
+	if v := f.Type(name); v != nil {
+		return v
+	}
+	return nil
+}
+
+func (file *File) ExtractNamed(tp ast.Expr) (Named, error) {
+	return file.ExtractNamedString(file.Printer.ToString(typeName(tp)))
+}
+
+// ExtractNamedString finds any declared type (struct, interface or other named type) locally or in imported packages.
+// Results are remembered by the file
+func (file *File) ExtractNamedString(tp string) (Named, error) {
+	if iface := builtin().Interface(tp); iface != nil {
+		return iface, nil
+	}
+	file.lookups.lock.Lock()
+	found, ok := file.lookups.named[tp]
+	file.lookups.lock.Unlock()
+	if ok {
+		return found.named, found.err
+	}
+	found = &namedLookup{}
+	src, name, err := file.lookupType(tp, func(f *File, name string) bool { return f.Named(name) != nil })
+	if err != nil {
+		found.err = err
+	} else {
+		found.named = src.Named(name)
+	}
+	file.lookups.lock.Lock()
+	if file.lookups.named == nil {
+		file.lookups.named = make(map[string]*namedLookup)
+	}
+	file.lookups.named[tp] = found
+	file.lookups.lock.Unlock()
+	return found.named, found.err
+}
@@ -0,0 +1,50 @@
+package atool
+
+import (
+	"encoding/json"
+	"github.com/alecthomas/assert"
+	"testing"
+)
+
+func TestFile_Types(t *testing.T) {
+	f, err := Scan("test/testdata/types/types.go")
+	assert.Nil(t, err)
+	var names []string
+	for _, tp := range f.Types {
+		names = append(names, tp.Name)
+	}
+	assert.Equal(t, []string{"Status", "Handler", "Handlers", "IDs", "Header", "Set"}, names)
+
+	status := f.Type("Status")
+	assert.Equal(t, "Status of task\n", status.Comment)
+	assert.Equal(t, "int", status.GolangType())
+	assert.Equal(t, "type Status int", status.GoLang())
+	assert.False(t, status.IsAlias)
+	assert.NotNil(t, status.Method("String"))
+
+	assert.Equal(t, "func(w http.ResponseWriter, r *http.Request) error", f.Type("Handler").GolangType())
+	assert.True(t, f.Type("Header").IsAlias)
+	assert.Equal(t, "type Header = http.Header", f.Type("Header").GoLang())
+	assert.Equal(t, "type Set[T comparable] map[T]struct{}", f.Type("Set").GoLang())
+
+	named, err := f.ExtractNamedString("Handlers")
+	assert.Nil(t, err)
+	assert.Equal(t, "Handlers", named.TypeName())
+	assert.Equal(t, f, named.Source())
+
+	named, err = f.ExtractNamedString("http.Header")
+	assert.Nil(t, err)
+	assert.Equal(t, "type Header map[string][]string", named.GoLang())
+	assert.Equal(t, "net/http", named.Source().Import)
+
+	named, err = f.ExtractNamedString("Task")
+	assert.Nil(t, err)
+	assert.Equal(t, f.Struct("Task"), named)
+
+	_, err = f.ExtractNamedString("Unknown")
+	assert.NotNil(t, err)
+
+	data, err := json.Marshal(status)
+	assert.Nil(t, err)
+	assert.Contains(t, string(data), `"GolangType":"int"`)
+}
@@ -0,0 +1,196 @@
+package atool
+
+import (
+	"github.com/Masterminds/semver"
+	"github.com/pkg/errors"
+	"os"
+	"path/filepath"
+)
+
+// Workspace is a parsed go.work file
+type Workspace struct {
+	Dir     string    // directory of go.work
+	Modules []*Module // modules from use directives
+	Replace []*Replace
+}
+
+// FindWorkspace looks for go.work in the directory and all parents with respect to GOWORK variable.
+// Returns nil without error if there is no workspace
+func FindWorkspace(dir string) (*Workspace, error) {
+	switch env := os.Getenv("GOWORK"); env {
+	case "off":
+		return nil, nil
+	case "":
+	default:
+		return ParseWorkFile(env)
+	}
+	dir, err := filepath.Abs(dir)
+	if err != nil {
+		return nil, err
+	}
+	for {
+		filename := filepath.Join(dir, "go.work")
+		if st, err := os.Stat(filename); err == nil && !st.IsDir() {
+			return ParseWorkFile(filename)
+		}
+		up := filepath.Dir(dir)
+		if up == dir {
+			return nil, nil
+		}
+		dir = up
+	}
+}
+
+// ParseWorkFile reads used modules and replacements from go.work
+func ParseWorkFile(filename string) (*Workspace, error) {
+	dir, err := filepath.Abs(filepath.Dir(filename))
+	if err != nil {
+		return nil, err
+	}
+	ws := &Workspace{Dir: dir}
+	err = parseDirectives(filename, func(verb string, args []string) error {
+		switch verb {
+		case "use":
+			if len(args) != 1 {
+				return errors.New("usage: use local/dir")
+			}
+			modDir := args[0]
+			if !filepath.IsAbs(modDir) {
+				modDir = filepath.Join(dir, modDir)
+			}
+			mod, err := ParseModFile(filepath.Join(modDir, "go.mod"))
+			if err != nil {
+				return err
+			}
+			ws.Modules = append(ws.Modules, mod)
+		case "replace":
+			replace, err := parseReplace(args, dir)
+			if err != nil {
+				return err
+			}
+			ws.Replace = append(ws.Replace, replace)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return ws, nil
+}
+
+// Module returns used module by path or nil
+func (ws *Workspace) Module(path string) *Module {
+	for _, mod := range ws.Modules {
+		if mod.Path == path {
+			return mod
+		}
+	}
+	return nil
+}
+
+// ResolveImport returns directory of imported package: inside one of used modules or in requirements of them.
+// Replacements of go.work have priority over replacements of modules. Returns empty string if package is not found
+func (ws *Workspace) ResolveImport(importPath string) string {
+	var owner *Module
+	for _, mod := range ws.Modules {
+		if _, ok := subPath(mod.Path, importPath); ok && (owner == nil || len(mod.Path) > len(owner.Path)) {
+			owner = mod
+		}
+	}
+	if owner != nil {
+		sub, _ := subPath(owner.Path, importPath)
+		return existingDir(filepath.Join(owner.Dir, sub))
+	}
+
+	replaces := ws.Replace
+	for _, mod := range ws.Modules {
+		replaces = append(replaces, mod.Replace...)
+	}
+	// like minimal version selection: the highest required version wins
+	var best, version string
+	for _, mod := range ws.Modules {
+		for path, ver := range mod.Require {
+			if _, ok := subPath(path, importPath); !ok || len(path) < len(best) {
+				continue
+			}
+			if path != best || newerVersion(ver, version) {
+				best, version = path, ver
+			}
+		}
+	}
+	for _, r := range replaces {
+		if _, ok := subPath(r.Old, importPath); ok && len(r.Old) > len(best) {
+			best, version = r.Old, r.OldVersion
+		}
+	}
+	if best == "" {
+		return ""
+	}
+	sub, _ := subPath(best, importPath)
+	return resolveRequirement(replaces, best, version, sub)
+}
+
+func newerVersion(a, b string) bool {
+	va, err := semver.NewVersion(a)
+	if err != nil {
+		return false
+	}
+	vb, err := semver.NewVersion(b)
+	if err != nil {
+		return true
+	}
+	return va.GreaterThan(vb)
+}
+
+// resolveModuleImport finds imported package in the workspace or, if there is no workspace, in the module of the directory
+func resolveModuleImport(dir, importPath string) (string, error) {
+	ws, err := FindWorkspace(dir)
+	if err != nil {
+		return "", errors.Wrap(err, "find workspace")
+	}
+	if ws != nil {
+		return ws.ResolveImport(importPath), nil
+	}
+	mod, err := FindModule(dir)
+	if err != nil {
+		return "", errors.Wrap(err, "find module")
+	}
+	if mod == nil {
+		return "", nil
+	}
+	return mod.ResolveImport(importPath), nil
+}
+
+// ResolvePackage returns directory of package defined by existing path (relative to base directory), import path
+// (resolved by the workspace or the module of base directory) or path relative to the workspace root
+func ResolvePackage(baseDir, pattern string) (string, error) {
+	path := pattern
+	if !filepath.IsAbs(path) {
+		path = filepath.Join(baseDir, pattern)
+	}
+	if _, err := os.Stat(path); err == nil {
+		return path, nil
+	}
+	dir, err := resolveModuleImport(baseDir, pattern)
+	if err != nil {
+		return "", err
+	}
+	if dir != "" {
+		return dir, nil
+	}
+	ws, err := FindWorkspace(baseDir)
+	if err != nil {
+		return "", errors.Wrap(err, "find workspace")
+	}
+	if ws != nil {
+		if dir := filepath.Join(ws.Dir, pattern); existingDir(dir) != "" || fileExists(dir) {
+			return dir, nil
+		}
+	}
+	return "", errors.Errorf("package %v not found", pattern)
+}
+
+func fileExists(filename string) bool {
+	st, err := os.Stat(filename)
+	return err == nil && !st.IsDir()
+}
@@ -0,0 +1,59 @@
+package atool
+
+import (
+	"github.com/alecthomas/assert"
+	"path/filepath"
+	"testing"
+)
+
+func TestFindWorkspace(t *testing.T) {
+	ws, err := FindWorkspace("test/testdata/workspace/a/api")
+	assert.Nil(t, err)
+	root, _ := filepath.Abs("test/testdata/workspace")
+	assert.Equal(t, root, ws.Dir)
+	assert.Len(t, ws.Modules, 2)
+	assert.NotNil(t, ws.Module("example.com/b"))
+	assert.Len(t, ws.Replace, 1)
+
+	assert.Equal(t, filepath.Join(root, "b/model"), ws.ResolveImport("example.com/b/model"))
+	assert.Equal(t, filepath.Join(root, "ext"), ws.ResolveImport("example.com/ext"))
+	assert.Equal(t, "", ws.ResolveImport("example.com/c"))
+
+	ws, err = FindWorkspace("test/testdata/modules/app")
+	assert.Nil(t, err)
+	assert.Nil(t, ws)
+}
+
+func TestFile_ExtractType_workspace(t *testing.T) {
+	f, err := Scan("test/testdata/workspace/a/api/api.go")
+	assert.Nil(t, err)
+	st, err := f.ExtractTypeString("model.User")
+	assert.Nil(t, err)
+	assert.Equal(t, "example.com/b/model", st.File.Import)
+	st, err = f.ExtractTypeString("ext.Extra")
+	assert.Nil(t, err)
+	assert.NotNil(t, st.Field("Value"))
+}
+
+func TestResolvePackage(t *testing.T) {
+	root, _ := filepath.Abs("test/testdata/workspace")
+	base := "test/testdata/workspace/a"
+	dir, err := ResolvePackage(base, "example.com/b/model")
+	assert.Nil(t, err)
+	assert.Equal(t, filepath.Join(root, "b/model"), dir)
+
+	dir, err = ResolvePackage(base, "b/model")
+	assert.Nil(t, err)
+	assert.Equal(t, filepath.Join(root, "b/model"), dir)
+
+	dir, err = ResolvePackage(base, "api/api.go")
+	assert.Nil(t, err)
+	assert.Equal(t, filepath.Join(base, "api/api.go"), dir)
+
+	// existing paths are relative to base directory, not to working directory
+	_, err = ResolvePackage(base, "test/sample.go")
+	assert.NotNil(t, err)
+
+	_, err = ResolvePackage(base, "example.com/unknown")
+	assert.NotNil(t, err)
+}